@@ -0,0 +1,122 @@
+package gobdd
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	durationType        = reflect.TypeOf(time.Duration(0))
+	timeType            = reflect.TypeOf(time.Time{})
+	bytesType           = reflect.TypeOf([]byte{})
+)
+
+// timeLayouts are layouts accepted by time.Time parameters
+var timeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02", "15:04:05"}
+
+// convertValue converts the text captured from a step (or a data table's cell) into a value of the type.
+// It supports strings, booleans, numbers, time.Duration, time.Time, []byte, types implementing
+// encoding.TextUnmarshaler and pointers to all of them.
+func convertValue(text string, t reflect.Type) (reflect.Value, error) {
+	switch {
+	case t == durationType:
+		d, err := time.ParseDuration(text)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		return reflect.ValueOf(d), nil
+	case t == timeType:
+		for _, layout := range timeLayouts {
+			if tm, err := time.Parse(layout, text); err == nil {
+				return reflect.ValueOf(tm), nil
+			}
+		}
+
+		return reflect.Value{}, fmt.Errorf("the time should be in one of the formats: %v", timeLayouts)
+	case t == bytesType:
+		return reflect.ValueOf([]byte(text)), nil
+	case t.Kind() != reflect.Ptr && reflect.PtrTo(t).Implements(textUnmarshalerType):
+		v := reflect.New(t)
+		if err := v.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(text)); err != nil {
+			return reflect.Value{}, err
+		}
+
+		return v.Elem(), nil
+	case t.Kind() == reflect.Ptr:
+		elem, err := convertValue(text, t.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		v := reflect.New(t.Elem())
+		v.Elem().Set(elem)
+
+		return v, nil
+	}
+
+	v := reflect.New(t).Elem()
+
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString(text)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(text, 10, t.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(text, 10, t.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(text, t.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		v.SetFloat(f)
+	default:
+		return reflect.Value{}, fmt.Errorf("unsupported type %s", t)
+	}
+
+	return v, nil
+}
+
+// isConvertible tells whether convertValue supports the type
+func isConvertible(t reflect.Type) bool {
+	switch {
+	case t == durationType, t == timeType, t == bytesType:
+		return true
+	case t.Kind() != reflect.Ptr && reflect.PtrTo(t).Implements(textUnmarshalerType):
+		return true
+	case t.Kind() == reflect.Ptr:
+		return isConvertible(t.Elem())
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+
+	return false
+}
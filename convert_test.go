@@ -0,0 +1,122 @@
+package gobdd
+
+import (
+	"math/big"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-bdd/assert"
+)
+
+type level string
+
+func TestConvertValue(t *testing.T) {
+	testCases := map[string]struct {
+		text     string
+		expected interface{}
+	}{
+		"string":           {text: "pizza", expected: "pizza"},
+		"named string":     {text: "high", expected: level("high")},
+		"bool":             {text: "true", expected: true},
+		"int":              {text: "-42", expected: -42},
+		"int8":             {text: "-128", expected: int8(-128)},
+		"int16":            {text: "1024", expected: int16(1024)},
+		"int32":            {text: "70000", expected: int32(70000)},
+		"int64":            {text: "9000000000", expected: int64(9000000000)},
+		"uint":             {text: "42", expected: uint(42)},
+		"uint8":            {text: "255", expected: uint8(255)},
+		"uint16":           {text: "65535", expected: uint16(65535)},
+		"uint32":           {text: "70000", expected: uint32(70000)},
+		"uint64":           {text: "9000000000", expected: uint64(9000000000)},
+		"float32":          {text: "1.5", expected: float32(1.5)},
+		"float64":          {text: "0.1", expected: 0.1},
+		"duration":         {text: "1m30s", expected: 90 * time.Second},
+		"time":             {text: "2020-05-17T10:00:00Z", expected: time.Date(2020, 5, 17, 10, 0, 0, 0, time.UTC)},
+		"date":             {text: "2020-05-17", expected: time.Date(2020, 5, 17, 0, 0, 0, 0, time.UTC)},
+		"bytes":            {text: "abc", expected: []byte("abc")},
+		"text unmarshaler": {text: "127.0.0.1", expected: net.ParseIP("127.0.0.1")},
+		"pointer":          {text: "42", expected: func() *int { i := 42; return &i }()},
+		"big float":        {text: "0.1", expected: func() *big.Float { f, _ := new(big.Float).SetString("0.1"); return f }()},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			value, err := convertValue(testCase.text, reflect.TypeOf(testCase.expected))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(testCase.expected, value.Interface()) {
+				t.Errorf("expected %#v but %#v received", testCase.expected, value.Interface())
+			}
+		})
+	}
+}
+
+func TestConvertValue_Errors(t *testing.T) {
+	testCases := map[string]struct {
+		text string
+		t    reflect.Type
+	}{
+		"bool":             {text: "yes please", t: reflect.TypeOf(true)},
+		"int":              {text: "1.5", t: reflect.TypeOf(0)},
+		"int8 overflow":    {text: "128", t: reflect.TypeOf(int8(0))},
+		"negative uint":    {text: "-1", t: reflect.TypeOf(uint(0))},
+		"float":            {text: "abc", t: reflect.TypeOf(0.0)},
+		"duration":         {text: "5 minutes", t: reflect.TypeOf(time.Second)},
+		"time":             {text: "yesterday", t: reflect.TypeOf(time.Time{})},
+		"text unmarshaler": {text: "localhost", t: reflect.TypeOf(net.IP{})},
+		"pointer":          {text: "abc", t: reflect.TypeOf(new(int))},
+		"unsupported":      {text: "a", t: reflect.TypeOf([]string{})},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := convertValue(testCase.text, testCase.t); err == nil {
+				t.Errorf("the conversion of %q to %s should fail", testCase.text, testCase.t)
+			}
+		})
+	}
+}
+
+func TestIsConvertible(t *testing.T) {
+	testCases := map[reflect.Type]bool{
+		reflect.TypeOf(""):               true,
+		reflect.TypeOf(level("")):        true,
+		reflect.TypeOf(uint16(0)):        true,
+		reflect.TypeOf(float32(0)):       true,
+		reflect.TypeOf(time.Second):      true,
+		reflect.TypeOf(time.Time{}):      true,
+		reflect.TypeOf([]byte{}):         true,
+		reflect.TypeOf(net.IP{}):         true,
+		reflect.TypeOf(new(int)):         true,
+		reflect.TypeOf(new(big.Float)):   true,
+		reflect.TypeOf([]string{}):       false,
+		reflect.TypeOf(map[string]int{}): false,
+		reflect.TypeOf(struct{}{}):       false,
+		reflect.TypeOf(make(chan int)):   false,
+		reflect.TypeOf(new(interface{})): false,
+	}
+
+	for typ, expected := range testCases {
+		if err := assert.Equals(expected, isConvertible(typ)); err != nil {
+			t.Errorf("%s: %s", typ, err)
+		}
+	}
+}
+
+func TestConversionFailure(t *testing.T) {
+	def := stepDef{f: func(t StepTest, ctx Context, name string, count uint8) {
+		t.Error("the step should not be called")
+	}}
+
+	tester := &mockTester{}
+	def.run(NewContext(), tester, [][]byte{[]byte("pizza"), []byte("300")}, nil, "features/pizza.feature:4")
+
+	expected := []string{`cannot convert argument 2 "300" to uint8: strconv.ParseUint: parsing "300": value out of range (features/pizza.feature:4)`}
+	if err := assert.Equals(expected, tester.errors); err != nil {
+		t.Error(err)
+	}
+}
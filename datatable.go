@@ -0,0 +1,164 @@
+package gobdd
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	msgs "github.com/cucumber/messages-go/v12"
+)
+
+// DataTable holds the rows of a Gherkin data table placed below a step.
+//
+// A step function receives the table when it accepts a DataTable parameter (at any position):
+//
+// 	func myStepFunction(t gobdd.StepTest, ctx gobdd.Context, table gobdd.DataTable) {
+// 	}
+type DataTable struct {
+	rows [][]string
+}
+
+func newDataTable(table *msgs.GherkinDocument_Feature_Step_DataTable) DataTable {
+	rows := make([][]string, 0, len(table.GetRows()))
+
+	for _, row := range table.GetRows() {
+		cells := make([]string, 0, len(row.GetCells()))
+		for _, cell := range row.GetCells() {
+			cells = append(cells, cell.GetValue())
+		}
+
+		rows = append(rows, cells)
+	}
+
+	return DataTable{rows: rows}
+}
+
+// Rows returns all the rows of the table (including the header)
+func (dt DataTable) Rows() [][]string {
+	return dt.rows
+}
+
+// Header returns the first row of the table
+func (dt DataTable) Header() []string {
+	if len(dt.rows) == 0 {
+		return nil
+	}
+
+	return dt.rows[0]
+}
+
+// Maps returns every row except the header as a map keyed by the header's cells
+func (dt DataTable) Maps() []map[string]string {
+	header := dt.Header()
+	if len(header) == 0 {
+		return nil
+	}
+
+	maps := make([]map[string]string, 0, len(dt.rows)-1)
+
+	for _, row := range dt.rows[1:] {
+		m := make(map[string]string, len(header))
+		for i, key := range header {
+			m[key] = row[i]
+		}
+
+		maps = append(maps, m)
+	}
+
+	return maps
+}
+
+// Decode fills the slice of structs the target points to with the rows of the table.
+// The first row is the header. Every header cell is matched against the `table` tag of the struct's fields
+// or, if the tag is missing, against the field's name (case insensitive).
+// Fields can have the same types as parameters of step functions.
+//
+// 	var users []struct {
+// 		Name string `table:"name"`
+// 		Age  int    `table:"age"`
+// 	}
+// 	err := table.Decode(&users)
+func (dt DataTable) Decode(target interface{}) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Slice {
+		return errors.New("the target should be a pointer to a slice of structs")
+	}
+
+	slice := value.Elem()
+	elemType := slice.Type().Elem()
+
+	if elemType.Kind() != reflect.Struct {
+		return errors.New("the target should be a pointer to a slice of structs")
+	}
+
+	header := dt.Header()
+	if len(header) == 0 {
+		slice.Set(reflect.MakeSlice(slice.Type(), 0, 0))
+
+		return nil
+	}
+
+	fields := make([]int, len(header))
+
+	for i, key := range header {
+		index, ok := tableField(elemType, key)
+		if !ok {
+			return fmt.Errorf("the column %q does not match any field of %s", key, elemType)
+		}
+
+		fields[i] = index
+	}
+
+	rows := reflect.MakeSlice(slice.Type(), 0, len(dt.rows)-1)
+
+	for r, row := range dt.rows[1:] {
+		elem := reflect.New(elemType).Elem()
+
+		for i, cell := range row {
+			if err := setTableField(elem.Field(fields[i]), cell); err != nil {
+				return fmt.Errorf("cannot decode row %d, column %q: %v", r+1, header[i], err)
+			}
+		}
+
+		rows = reflect.Append(rows, elem)
+	}
+
+	slice.Set(rows)
+
+	return nil
+}
+
+func tableField(t reflect.Type, column string) (int, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if tag, ok := field.Tag.Lookup("table"); ok {
+			if tag == column {
+				return i, true
+			}
+
+			continue
+		}
+
+		if strings.EqualFold(field.Name, column) {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+func setTableField(field reflect.Value, value string) error {
+	v, err := convertValue(value, field.Type())
+	if err != nil {
+		return err
+	}
+
+	field.Set(v)
+
+	return nil
+}
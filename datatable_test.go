@@ -0,0 +1,83 @@
+package gobdd
+
+import (
+	"testing"
+	"time"
+
+	msgs "github.com/cucumber/messages-go/v12"
+	"github.com/go-bdd/assert"
+)
+
+func TestDataTable_Maps(t *testing.T) {
+	table := newTestDataTable([]string{"name", "age"}, []string{"John", "30"})
+
+	expected := []map[string]string{{"name": "John", "age": "30"}}
+	if err := assert.Equals(expected, table.Maps()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDataTable_Decode(t *testing.T) {
+	type user struct {
+		Name   string
+		Age    int     `table:"age in years"`
+		Height float64 `table:"height"`
+		Admin  bool
+		Idle   time.Duration
+	}
+
+	table := newTestDataTable(
+		[]string{"name", "age in years", "height", "admin", "idle"},
+		[]string{"John", "30", "1.83", "true", "5m"},
+		[]string{"Alice", "25", "1.65", "false", "0s"},
+	)
+
+	var users []user
+	if err := table.Decode(&users); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []user{
+		{Name: "John", Age: 30, Height: 1.83, Admin: true, Idle: 5 * time.Minute},
+		{Name: "Alice", Age: 25, Height: 1.65},
+	}
+	if err := assert.Equals(expected, users); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDataTable_DecodeErrors(t *testing.T) {
+	testCases := map[string]struct {
+		table  DataTable
+		target interface{}
+	}{
+		"target is not a pointer":  {table: newTestDataTable([]string{"name"}), target: []struct{ Name string }{}},
+		"target is not a slice":    {table: newTestDataTable([]string{"name"}), target: &struct{ Name string }{}},
+		"unknown column":           {table: newTestDataTable([]string{"surname"}), target: &[]struct{ Name string }{}},
+		"value cannot be parsed":   {table: newTestDataTable([]string{"age"}, []string{"old"}), target: &[]struct{ Age int }{}},
+		"unsupported field's type": {table: newTestDataTable([]string{"tags"}, []string{"a"}), target: &[]struct{ Tags []string }{}},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if err := testCase.table.Decode(testCase.target); err == nil {
+				t.Error("the decoding should fail")
+			}
+		})
+	}
+}
+
+func newTestDataTable(rows ...[]string) DataTable {
+	table := &msgs.GherkinDocument_Feature_Step_DataTable{}
+
+	for _, row := range rows {
+		tableRow := &msgs.GherkinDocument_Feature_TableRow{}
+		for _, cell := range row {
+			tableRow.Cells = append(tableRow.Cells, &msgs.GherkinDocument_Feature_TableRow_TableCell{Value: cell})
+		}
+
+		table.Rows = append(table.Rows, tableRow)
+	}
+
+	return newDataTable(table)
+}
@@ -0,0 +1,57 @@
+package gobdd
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+
+	msgs "github.com/cucumber/messages-go/v12"
+)
+
+// DocString holds the content of a Gherkin doc string placed below a step.
+//
+// A step function receives the doc string when it accepts a DocString parameter (at any position):
+//
+// 	func myStepFunction(t gobdd.StepTest, ctx gobdd.Context, body gobdd.DocString) {
+// 	}
+type DocString struct {
+	// Content is the text between the delimiters
+	Content string
+	// MediaType is the (optional) type written right after the opening delimiter, like `"""json`
+	MediaType string
+}
+
+func newDocString(docString *msgs.GherkinDocument_Feature_Step_DocString) DocString {
+	return DocString{
+		Content:   docString.GetContent(),
+		MediaType: docString.GetMediaType(),
+	}
+}
+
+// String returns the content of the doc string
+func (ds DocString) String() string {
+	return ds.Content
+}
+
+// DecodeJSON unmarshals the content into the target.
+// The media type of the doc string has to be JSON: `json`, `application/json` or a type with
+// the `+json` suffix (like `application/ld+json`). Parameters (like `charset=utf-8`) and case are ignored.
+func (ds DocString) DecodeJSON(target interface{}) error {
+	if !isJSONMediaType(ds.MediaType) {
+		return fmt.Errorf("the doc string's media type should be json but %q received", ds.MediaType)
+	}
+
+	return json.Unmarshal([]byte(ds.Content), target)
+}
+
+func isJSONMediaType(value string) bool {
+	mediaType, _, err := mime.ParseMediaType(value)
+	if err != nil {
+		return false
+	}
+
+	mediaType = strings.ToLower(mediaType)
+
+	return mediaType == "json" || mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
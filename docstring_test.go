@@ -0,0 +1,39 @@
+package gobdd
+
+import (
+	"testing"
+
+	"github.com/go-bdd/assert"
+)
+
+func TestDocString_DecodeJSON(t *testing.T) {
+	for _, mediaType := range []string{"json", "JSON", "application/json", "application/json; charset=utf-8", "application/ld+json"} {
+		t.Run(mediaType, func(t *testing.T) {
+			var target struct {
+				Name string `json:"name"`
+			}
+
+			ds := DocString{Content: `{"name": "John"}`, MediaType: mediaType}
+			if err := ds.DecodeJSON(&target); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := assert.Equals("John", target.Name); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestDocString_DecodeJSONInvalidMediaType(t *testing.T) {
+	for _, mediaType := range []string{"", "xml", "notjson", "application/xml", "text/json+xml"} {
+		t.Run(mediaType, func(t *testing.T) {
+			var target map[string]interface{}
+
+			ds := DocString{Content: `{"name": "John"}`, MediaType: mediaType}
+			if err := ds.DecodeJSON(&target); err == nil {
+				t.Error("decoding a doc string which is not JSON should fail")
+			}
+		})
+	}
+}
@@ -0,0 +1,389 @@
+package gobdd
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+const floatRegexp = `[-+]?(?:\d+(?:\.\d*)?|\.\d+)(?:[eE][-+]?\d+)?`
+
+// stringRegexps match double-quoted or single-quoted text, the value of the parameter is the text between quotes
+var stringRegexps = []string{`"([^"\\]*(?:\\.[^"\\]*)*)"`, `'([^'\\]*(?:\\.[^'\\]*)*)'`}
+
+type expressionNodeKind int
+
+const (
+	expressionText expressionNodeKind = iota
+	expressionWhitespace
+	expressionOptional
+	expressionAlternation
+	expressionParameter
+)
+
+// expressionNode is a part of a parsed Cucumber expression
+type expressionNode struct {
+	kind   expressionNodeKind
+	text   string
+	column int
+}
+
+// expressionParam points to the regular expression's groups holding the value of a parameter.
+// The value is taken from the first matched inner group (like the text between quotes of {string})
+// or from the whole parameter if the parameter type's regular expression has no groups.
+type expressionParam struct {
+	name        string
+	group       int
+	inner       []int
+	transformer reflect.Value
+}
+
+func (p expressionParam) value(match [][]byte) []byte {
+	for _, i := range p.inner {
+		if match[i] != nil {
+			return match[i]
+		}
+	}
+
+	return match[p.group]
+}
+
+// transform converts the parameter's value using the transformer of the parameter type
+func (p expressionParam) transform(value []byte, inType reflect.Type) (reflect.Value, error) {
+	out := p.transformer.Call([]reflect.Value{reflect.ValueOf(string(value)).Convert(p.transformer.Type().In(0))})
+	if err, _ := out[1].Interface().(error); err != nil {
+		return reflect.Value{}, fmt.Errorf("cannot transform %q into {%s}: %v", value, p.name, err)
+	}
+
+	if !out[0].Type().AssignableTo(inType) {
+		return reflect.Value{}, fmt.Errorf("the parameter {%s} is %s but the step function expects %s", p.name, out[0].Type(), inType)
+	}
+
+	return out[0], nil
+}
+
+// looksLikeRegexp tells whether the step's expression should be treated as a regular expression
+// instead of a Cucumber expression. It is true for anchored expressions (^ or $), expressions escaping
+// characters which cannot be escaped in Cucumber expressions (like \d), expressions with groups
+// using special characters of regular expressions (like `(\d+)`) and valid regular expressions
+// using character classes, quantifiers or alternations outside groups (like `I have [0-9]+ apples`).
+// A question mark at the end of a word followed by a space or the end of the expression is treated as text.
+func looksLikeRegexp(expr string) bool {
+	if strings.HasPrefix(expr, "^") || strings.HasSuffix(expr, "$") {
+		return true
+	}
+
+	runes := []rune(expr)
+	group := -1
+	metacharacters := false
+
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case r == '\\':
+			if group >= 0 || i+1 == len(runes) || !isEscapable(runes[i+1]) {
+				return true
+			}
+
+			i++
+		case r == '(':
+			group = i + 1
+		case r == ')' && group >= 0:
+			if strings.ContainsAny(string(runes[group:i]), `[].*+?|^$`) {
+				return true
+			}
+
+			group = -1
+		case group >= 0:
+		case r == '[' || r == ']' || r == '|':
+			metacharacters = true
+		case (r == '*' || r == '+') && i > 0 && !unicode.IsSpace(runes[i-1]):
+			metacharacters = true
+		case r == '?' && i > 0 && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]):
+			metacharacters = true
+		}
+	}
+
+	if !metacharacters {
+		return false
+	}
+
+	_, err := regexp.Compile(expr)
+
+	return err == nil
+}
+
+// compileExpression compiles the Cucumber expression into an anchored regular expression.
+// Every parameter type used by the expression has to be defined in parameterTypes.
+func compileExpression(expr string, parameterTypes map[string][]string) (*regexp.Regexp, []expressionParam, error) {
+	nodes, err := parseExpression(expr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		pattern strings.Builder
+		params  []expressionParam
+		segment []expressionNode
+	)
+
+	groups := 0
+
+	flush := func() error {
+		if len(segment) == 0 {
+			return nil
+		}
+
+		compiled, err := compileSegment(expr, segment)
+		if err != nil {
+			return err
+		}
+
+		pattern.WriteString(compiled)
+		segment = nil
+
+		return nil
+	}
+
+	pattern.WriteString("^")
+
+	for _, node := range nodes {
+		switch node.kind {
+		case expressionWhitespace:
+			if err := flush(); err != nil {
+				return nil, nil, err
+			}
+
+			pattern.WriteString(regexp.QuoteMeta(node.text))
+		case expressionParameter:
+			if err := flush(); err != nil {
+				return nil, nil, err
+			}
+
+			regexps, ok := parameterTypes["{"+node.text+"}"]
+			if !ok {
+				return nil, nil, expressionError(expr, node.column, "the parameter type {%s} is not defined", node.text)
+			}
+
+			groups++
+			param := expressionParam{name: node.text, group: groups}
+
+			for _, re := range regexps {
+				if n := regexp.MustCompile(re).NumSubexp(); n > 0 {
+					param.inner = append(param.inner, groups+1)
+					groups += n
+				}
+			}
+
+			pattern.WriteString("(" + strings.Join(regexps, "|") + ")")
+			params = append(params, param)
+		default:
+			segment = append(segment, node)
+		}
+	}
+
+	if err := flush(); err != nil {
+		return nil, nil, err
+	}
+
+	pattern.WriteString("$")
+
+	compiled, err := regexp.Compile(pattern.String())
+	if err != nil {
+		return nil, nil, fmt.Errorf("the expression \"%s\" cannot be compiled: %v", expr, err)
+	}
+
+	return compiled, params, nil
+}
+
+// compileSegment compiles text and optional text placed between whitespaces and parameters.
+// Slashes split the segment into alternatives.
+func compileSegment(expr string, segment []expressionNode) (string, error) {
+	alternatives := [][]expressionNode{{}}
+	columns := []int{segment[0].column}
+
+	for _, node := range segment {
+		if node.kind == expressionAlternation {
+			alternatives = append(alternatives, []expressionNode{})
+			columns = append(columns, node.column+1)
+
+			continue
+		}
+
+		alternatives[len(alternatives)-1] = append(alternatives[len(alternatives)-1], node)
+	}
+
+	compiled := make([]string, 0, len(alternatives))
+
+	for i, alternative := range alternatives {
+		if len(alternatives) > 1 {
+			if len(alternative) == 0 {
+				return "", expressionError(expr, columns[i], "an alternative cannot be empty")
+			}
+
+			onlyOptional := true
+			for _, node := range alternative {
+				onlyOptional = onlyOptional && node.kind == expressionOptional
+			}
+
+			if onlyOptional {
+				return "", expressionError(expr, columns[i], "an alternative cannot contain only optional text")
+			}
+		}
+
+		var b strings.Builder
+		for _, node := range alternative {
+			if node.kind == expressionOptional {
+				b.WriteString("(?:" + regexp.QuoteMeta(node.text) + ")?")
+				continue
+			}
+
+			b.WriteString(regexp.QuoteMeta(node.text))
+		}
+
+		compiled = append(compiled, b.String())
+	}
+
+	if len(compiled) == 1 {
+		return compiled[0], nil
+	}
+
+	return "(?:" + strings.Join(compiled, "|") + ")", nil
+}
+
+// parseExpression splits the Cucumber expression into text, whitespaces, optional text (in parentheses),
+// alternation separators (slashes) and parameters (in curly braces).
+// A backslash escapes the next character.
+func parseExpression(expr string) ([]expressionNode, error) {
+	runes := []rune(expr)
+
+	var (
+		nodes []expressionNode
+		text  strings.Builder
+	)
+
+	textColumn := 0
+
+	flush := func() {
+		if text.Len() > 0 {
+			nodes = append(nodes, expressionNode{kind: expressionText, text: text.String(), column: textColumn})
+			text.Reset()
+		}
+	}
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		column := i + 1
+
+		switch {
+		case r == '\\':
+			if i+1 == len(runes) {
+				return nil, expressionError(expr, column, "the expression cannot end with an escape character")
+			}
+
+			i++
+			if !isEscapable(runes[i]) {
+				return nil, expressionError(expr, column, "only whitespaces and the characters {}()/\\ can be escaped")
+			}
+
+			if text.Len() == 0 {
+				textColumn = column
+			}
+
+			text.WriteRune(runes[i])
+		case unicode.IsSpace(r):
+			flush()
+			nodes = append(nodes, expressionNode{kind: expressionWhitespace, text: string(r), column: column})
+		case r == '(':
+			flush()
+
+			content, end, err := readExpressionGroup(expr, runes, i, ')')
+			if err != nil {
+				return nil, err
+			}
+
+			if content == "" {
+				return nil, expressionError(expr, column, "an optional text cannot be empty")
+			}
+
+			nodes = append(nodes, expressionNode{kind: expressionOptional, text: content, column: column})
+			i = end
+		case r == '{':
+			flush()
+
+			content, end, err := readExpressionGroup(expr, runes, i, '}')
+			if err != nil {
+				return nil, err
+			}
+
+			nodes = append(nodes, expressionNode{kind: expressionParameter, text: content, column: column})
+			i = end
+		case r == ')' || r == '}':
+			return nil, expressionError(expr, column, "the %c has no matching opening character", r)
+		case r == '/':
+			flush()
+			nodes = append(nodes, expressionNode{kind: expressionAlternation, text: "/", column: column})
+		default:
+			if text.Len() == 0 {
+				textColumn = column
+			}
+
+			text.WriteRune(r)
+		}
+	}
+
+	flush()
+
+	return nodes, nil
+}
+
+// readExpressionGroup reads the content of an optional text or a parameter starting at the position start
+func readExpressionGroup(expr string, runes []rune, start int, closing rune) (string, int, error) {
+	var content strings.Builder
+
+	for i := start + 1; i < len(runes); i++ {
+		r := runes[i]
+		column := i + 1
+
+		switch {
+		case r == closing:
+			return content.String(), i, nil
+		case closing == ')' && r == '\\':
+			if i+1 == len(runes) {
+				return "", 0, expressionError(expr, column, "the expression cannot end with an escape character")
+			}
+
+			i++
+			if !isEscapable(runes[i]) {
+				return "", 0, expressionError(expr, column, "only whitespaces and the characters {}()/\\ can be escaped")
+			}
+
+			content.WriteRune(runes[i])
+		case closing == ')' && r == '(':
+			return "", 0, expressionError(expr, column, "an optional text cannot be nested")
+		case closing == ')' && r == '{':
+			return "", 0, expressionError(expr, column, "a parameter type cannot be optional")
+		case closing == ')' && r == '/':
+			return "", 0, expressionError(expr, column, "an alternation cannot be used inside an optional text")
+		case closing == '}' && (strings.ContainsRune(`{()/\`, r) || unicode.IsSpace(r)):
+			return "", 0, expressionError(expr, column, "the name of a parameter type cannot contain %q", r)
+		default:
+			content.WriteRune(r)
+		}
+	}
+
+	if closing == ')' {
+		return "", 0, expressionError(expr, start+1, "the optional text is not closed")
+	}
+
+	return "", 0, expressionError(expr, start+1, "the parameter type is not closed")
+}
+
+func isEscapable(r rune) bool {
+	return strings.ContainsRune(`{}()/\`, r) || unicode.IsSpace(r)
+}
+
+func expressionError(expr string, column int, format string, args ...interface{}) error {
+	return fmt.Errorf("the expression \"%s\" is invalid at column %d: %s", expr, column, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,132 @@
+package gobdd
+
+import (
+	"testing"
+
+	"github.com/go-bdd/assert"
+)
+
+func TestCompileExpression(t *testing.T) {
+	testCases := []struct {
+		expr     string
+		text     string
+		matches  bool
+		expected []string
+	}{
+		{expr: "I have {int} cukes", text: "I have 42 cukes", matches: true, expected: []string{"42"}},
+		{expr: "I have {int} cukes", text: "I have -7 cukes", matches: true, expected: []string{"-7"}},
+		{expr: "I have {int} cukes", text: "I have 42 cukes in my belly", matches: false},
+		{expr: "I have {float} cukes", text: "I have -.5 cukes", matches: true, expected: []string{"-.5"}},
+		{expr: "I have {float} cukes", text: "I have 1.5e3 cukes", matches: true, expected: []string{"1.5e3"}},
+		{expr: "I have {bigdecimal} cukes", text: "I have 0.1 cukes", matches: true, expected: []string{"0.1"}},
+		{expr: "I eat {word}", text: "I eat pizza", matches: true, expected: []string{"pizza"}},
+		{expr: "I eat {word}", text: "I eat two pizzas", matches: false},
+		{expr: "I say {string}", text: `I say "hello world"`, matches: true, expected: []string{"hello world"}},
+		{expr: "I say {string}", text: `I say 'hello world'`, matches: true, expected: []string{"hello world"}},
+		{expr: "I say {string}", text: `I say ""`, matches: true, expected: []string{""}},
+		{expr: "I say {text}", text: `I say "hi"`, matches: true, expected: []string{"hi"}},
+		{expr: "I say {}", text: "I say anything at all", matches: true, expected: []string{"anything at all"}},
+		{expr: "I have {int} cuke(s)", text: "I have 1 cuke", matches: true, expected: []string{"1"}},
+		{expr: "I have {int} cuke(s)", text: "I have 2 cukes", matches: true, expected: []string{"2"}},
+		{expr: "in my belly/stomach", text: "in my stomach", matches: true, expected: []string{}},
+		{expr: "in my belly/stomach", text: "in my belly", matches: true, expected: []string{}},
+		{expr: "in my belly/stomach", text: "in my belly/stomach", matches: false},
+		{expr: "I have a cuke/cucumber(s)", text: "I have a cucumbers", matches: true, expected: []string{}},
+		{expr: `I have \{int\} and \(s\)`, text: "I have {int} and (s)", matches: true, expected: []string{}},
+		{expr: `a\/b`, text: "a/b", matches: true, expected: []string{}},
+		{expr: "costs $1.50 (in total)", text: "costs $1.50 in total", matches: true, expected: []string{}},
+		{expr: "{int} and {string} and {int}", text: `1 and "x" and 3`, matches: true, expected: []string{"1", "x", "3"}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.expr+" / "+testCase.text, func(t *testing.T) {
+			s := NewSuite(t)
+			expr, params, err := compileExpression(testCase.expr, s.parameterTypes)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := assert.Equals(testCase.matches, expr.MatchString(testCase.text)); err != nil {
+				t.Fatal(err)
+			}
+
+			if !testCase.matches {
+				return
+			}
+
+			def := stepDef{expr: expr, params: params}
+			args := []string{}
+			for _, arg := range def.arguments(testCase.text) {
+				args = append(args, string(arg))
+			}
+
+			if err := assert.Equals(testCase.expected, args); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestCompileExpression_Errors(t *testing.T) {
+	testCases := map[string]string{
+		"I have {int":         "column 8: the parameter type is not closed",
+		"I have {int cukes":   "column 12: the name of a parameter type cannot contain ' '",
+		"I have int} cukes":   "column 11: the } has no matching opening character",
+		"I have {int} cuke(s": "column 18: the optional text is not closed",
+		"I have ()":           "column 8: an optional text cannot be empty",
+		"I have ((s))":        "column 9: an optional text cannot be nested",
+		"I have ({int})":      "column 9: a parameter type cannot be optional",
+		"I have (a/b)":        "column 10: an alternation cannot be used inside an optional text",
+		"I have {unknown}":    "column 8: the parameter type {unknown} is not defined",
+		"I have {in t}":       "column 11: the name of a parameter type cannot contain ' '",
+		"I have a/ cuke":      "column 10: an alternative cannot be empty",
+		"I have {int}/{int}":  "column 13: an alternative cannot be empty",
+		"I have a/(b) cuke":   "column 10: an alternative cannot contain only optional text",
+		`I have \n cukes`:     `column 8: only whitespaces and the characters {}()/\ can be escaped`,
+		`I have a cuke\`:      "column 14: the expression cannot end with an escape character",
+	}
+
+	for expr, expected := range testCases {
+		t.Run(expr, func(t *testing.T) {
+			s := NewSuite(t)
+			_, _, err := compileExpression(expr, s.parameterTypes)
+			if err == nil {
+				t.Fatalf("the expression %q should be invalid", expr)
+			}
+
+			if err := assert.Equals(`the expression "`+expr+`" is invalid at `+expected, err.Error()); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestLooksLikeRegexp(t *testing.T) {
+	testCases := map[string]bool{
+		`I add (\d+) and (\d+)`:         true,
+		`the name should equal "(\w+)"`: true,
+		`^I have a cuke`:                true,
+		`I have a cuke$`:                true,
+		`I have \d+ cukes`:              true,
+		`the value ([0-9]+)`:            true,
+		`I have [0-9]+ apples`:          true,
+		`I have \d+ apples?`:            true,
+		`the colou?r is red`:            true,
+		`I eat apples|pears`:            true,
+		`I have 5* apples`:              true,
+		`is the user logged in?`:        false,
+		`is it ok? yes`:                 false,
+		`I press the * key`:             false,
+		`I have [a cuke`:                false,
+		`I have {int} cuke(s)`:          false,
+		`I have \(a\) cuke`:             false,
+		`in my belly/stomach`:           false,
+		`the users:`:                    false,
+	}
+
+	for expr, expected := range testCases {
+		if err := assert.Equals(expected, looksLikeRegexp(expr)); err != nil {
+			t.Errorf("%s: %s", expr, err)
+		}
+	}
+}
@@ -0,0 +1,4 @@
+Feature: ambiguous steps
+  Scenario: the step matches two step definitions
+    When I add 1 and 2
+    Then the result should equal 3
@@ -0,0 +1,29 @@
+Feature: concurrency
+  Scenario: first
+    Given the scenario runs
+
+  Scenario: second
+    Given the scenario runs
+
+  Scenario Outline: outline <row>
+    Given the scenario runs
+
+    Examples:
+      | row |
+      | 1   |
+      | 2   |
+
+  @serial
+  Scenario: serial
+    Given the scenario runs alone
+
+  Scenario: third
+    Given the scenario runs
+
+  Rule: scenarios of a rule
+    Scenario: rule
+      Given the scenario runs
+
+    @serial
+    Scenario: serial rule
+      Given the scenario runs alone
@@ -0,0 +1,5 @@
+Feature: custom parameter types
+  Scenario: transform parameters into domain values
+    Given the car is red
+    When I pay 12.50 EUR for the car
+    Then the red car should cost 1250 cents
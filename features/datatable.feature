@@ -0,0 +1,7 @@
+Feature: data tables
+  Scenario: passing a data table to the step
+    Given the users:
+      | name  | age |
+      | John  | 30  |
+      | Alice | 25  |
+    Then there should be 2 users
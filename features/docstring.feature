@@ -0,0 +1,15 @@
+Feature: doc strings
+  Scenario: passing a doc string to the step
+    Given the text:
+      """
+      Hello
+      World
+      """
+    Then the text should have 2 lines
+
+  Scenario: passing a JSON doc string to the step
+    Given the payload:
+      """json
+      {"name": "John", "age": 30}
+      """
+    Then the name should equal "John"
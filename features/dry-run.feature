@@ -0,0 +1,17 @@
+Feature: dry run
+  Background:
+    Given I have 3 apples
+
+  Scenario: steps are checked
+    When I eat 2 apples
+    And I eat many apples
+    And I throw 1 apple
+    Then I have 1 apple
+
+  Scenario Outline: outlines are expanded
+    When I eat <count> apples
+
+    Examples:
+      | count |
+      | 1     |
+      | some  |
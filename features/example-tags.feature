@@ -0,0 +1,14 @@
+Feature: tags on examples
+  @outline
+  Scenario Outline: running tagged examples
+    Then the value <value> should be used
+    @smoke
+    Examples: small set
+      | value |
+      | 1     |
+
+    @slow
+    Examples: full matrix
+      | value |
+      | 2     |
+      | 3     |
@@ -0,0 +1,19 @@
+Feature: first feature
+  Background:
+    Given the step passes
+
+  Scenario: passing scenario
+    Given the step passes
+
+  Scenario: failing scenario
+    Given the step fails
+
+  Scenario Outline: outline after the failure
+    Given the step <result>
+
+    Examples:
+      | result |
+      | passes |
+
+  Scenario: scenario after the failure
+    Given the step passes
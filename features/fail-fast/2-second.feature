@@ -0,0 +1,19 @@
+Feature: second feature
+  Scenario: scenario after the failure
+    Given the step passes
+
+  @outline
+  Scenario Outline: outline with <result> steps
+    Given the step <result>
+
+    Examples:
+      | result |
+      | passes |
+      | fails  |
+
+  Rule: rule after the failure
+    Background:
+      Given the step passes
+
+    Scenario: scenario of the rule
+      Given the step passes
@@ -0,0 +1,13 @@
+Feature: failing steps
+  Scenario: a step fails
+    Given the step passes
+    When the step fails
+    Then the step passes
+    And the step is not implemented
+
+  Rule: the background fails
+    Background:
+      Given the step fails
+
+    Scenario: the background fails
+      Given the step passes
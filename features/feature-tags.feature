@@ -0,0 +1,12 @@
+@smoke
+Feature: feature tags
+  @api
+  Scenario: the scenario inherits the feature's tags
+    Then the scenario's tags should be "@smoke,@api"
+
+  Scenario Outline: the examples inherit the feature's tags
+    Then the scenario's tags should be "<tags>"
+    @example
+    Examples:
+      | tags            |
+      | @smoke,@example |
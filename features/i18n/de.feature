@@ -0,0 +1,5 @@
+# language: de
+Funktionalität: Mathematische Operationen
+  Szenario: zwei Zahlen addieren
+    Angenommen ich addiere 1 und 2
+    Dann ist das Ergebnis 3
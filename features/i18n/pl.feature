@@ -0,0 +1,4 @@
+Funkcja: działania matematyczne
+  Scenariusz: dodawanie dwóch liczb
+    Jeżeli dodam 1 i 2
+    Wtedy wynik wynosi 3
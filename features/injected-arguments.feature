@@ -0,0 +1,17 @@
+@injected
+Feature: injected arguments
+  Scenario: parameters in any order
+    Given I have 3 apples
+    When I eat 2 of them
+    Then the scenario info is injected
+    And the contexts are injected
+    And the table is injected:
+      | fruit | count |
+      | apple | 1     |
+
+  Scenario Outline: outline info
+    Then the scenario is named "<name>"
+
+    Examples:
+      | name         |
+      | outline info |
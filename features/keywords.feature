@@ -0,0 +1,12 @@
+Feature: keywords
+  Scenario: steps are matched by their keywords
+    Given the counter is 1
+    And the counter is 2
+    When I increment the counter
+    Then the counter is 3
+    But the counter is not 4
+
+  Scenario: a step is used with another keyword
+    Given the counter is 1
+    Then I increment the counter
+    And the counter is 2
@@ -0,0 +1,17 @@
+Feature: running scenarios by line
+  Scenario: the selected scenario
+    Then the scenario "first" should run
+
+  Scenario: the scenario which is not selected
+    Then fail the test
+
+  Scenario Outline: the outline with selected rows
+    Then the scenario "<name>" should run
+    Examples:
+      | name   |
+      | second |
+      | third  |
+    Examples:
+      | name   |
+      | fourth |
+      | fifth  |
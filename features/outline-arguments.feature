@@ -0,0 +1,14 @@
+Feature: placeholders in step arguments
+  Scenario Outline: substituting placeholders in data tables and doc strings
+    Given the users:
+      | name   | age   |
+      | <name> | <age> |
+    And the payload:
+      """<type>
+      {"name": "<name>"}
+      """
+    Then the user should be called <name> and the payload should be <type>
+    Examples:
+      | name  | age | type |
+      | John  | 30  | json |
+      | Alice | 25  | json |
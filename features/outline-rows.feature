@@ -0,0 +1,8 @@
+Feature: outline rows
+  Scenario Outline: adding <value> to the counter
+    When I add <value> to the counter
+    Then the counter should equal <value>
+    Examples:
+      | value |
+      | 1     |
+      | 2     |
@@ -10,4 +10,17 @@ Feature: parameter types
     When I use text 'I like pizza'
   Scenario: add two floats
     When I add floats 1 and 2
-    Then the result should equal float 3
\ No newline at end of file
+    Then the result should equal float 3
+  Scenario: add negative numbers with many digits
+    When I add -12 and 30
+    Then the result should equal 18
+  Scenario: simple string
+    When I use string "I like pizza"
+  Scenario: optional text and alternatives
+    When I eat 1 pizza
+    And I eat 2 pizzas
+    And I devour 3 pizzas
+    Then I ate 6 pizzas in total
+  Scenario: big decimals
+    When I pay 0.10 and 0.20
+    Then I paid 0.30
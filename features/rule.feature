@@ -0,0 +1,18 @@
+Feature: rules
+  Background:
+    Given the counter is 1
+
+  Scenario: scenario outside of rules
+    Then the counter should equal 1
+
+  Rule: incrementing
+    Background:
+      When I increment the counter
+
+    Scenario: the rule's background runs after the feature's one
+      When I increment the counter
+      Then the counter should equal 3
+
+  Rule: without background
+    Scenario: only the feature's background runs
+      Then the counter should equal 1
@@ -0,0 +1,15 @@
+Feature: step errors
+  Scenario: the step passes
+    Given the step passes
+
+  Scenario: the step returns an error
+    When the step returns an error
+
+  Scenario: the step is pending
+    When the step is pending
+
+  Scenario: the step is skipped
+    Then the step is skipped
+
+  Scenario: the step's argument cannot be converted
+    Given the step has 2.5 apples
@@ -0,0 +1,6 @@
+Feature: strict mode
+  Scenario: a step is pending
+    Given the step is pending
+
+  Scenario: a step is undefined
+    Given the step is not defined
@@ -0,0 +1,19 @@
+@api
+Feature: tag expressions
+  Scenario: the scenario without other tags should pass
+    Then the test should pass
+
+  @slow
+  Scenario: the slow scenario should never be executed
+    Then fail the test
+
+  @wip
+  Scenario Outline: the outline should run only the examples matching the expression
+    Then the test should pass
+    @smoke
+    Examples:
+      | value |
+      | 1     |
+    Examples:
+      | value |
+      | 2     |
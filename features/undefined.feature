@@ -0,0 +1,5 @@
+Feature: undefined steps
+  Scenario: the steps are not defined
+    Given I have 2 pizzas
+    When I eat 1 pizza "margherita"
+    Then I have 2 pizzas
@@ -1,20 +1,123 @@
 package cucumber
 
 import (
+	"fmt"
+	"strings"
+
 	msgs "github.com/cucumber/messages-go/v12"
 )
 
 func FormatFeatureWithScenario(gherkinFeature *msgs.GherkinDocument_Feature) Feature {
-	ft := GenerateFeature(gherkinFeature.GetName(), gherkinFeature.GetName(), gherkinFeature.GetDescription(), int(gherkinFeature.Location.GetLine()))
+	return FormatFeatureWithStatus(gherkinFeature, "")
+}
+
+// FormatFeatureWithStatus formats the feature with all its scenarios and gives every step the status.
+// Every row of a scenario outline's examples is formatted as a separate scenario.
+func FormatFeatureWithStatus(gherkinFeature *msgs.GherkinDocument_Feature, status string) Feature {
+	ft := FormatFeature(gherkinFeature)
+
+	var backgrounds []*msgs.GherkinDocument_Feature_Background
 
 	for _, child := range gherkinFeature.Children {
-		ft.AddScenario(FormatScenarioWithSteps(child.GetScenario(), ""))
+		if background := child.GetBackground(); background != nil {
+			backgrounds = append(backgrounds, background)
+		}
+
+		if rule := child.GetRule(); rule != nil {
+			ruleBackgrounds := backgrounds
+
+			for _, ruleChild := range rule.Children {
+				if background := ruleChild.GetBackground(); background != nil {
+					ruleBackgrounds = append(ruleBackgrounds[:len(ruleBackgrounds):len(ruleBackgrounds)], background)
+				}
+
+				if ruleChild.GetScenario() != nil {
+					formatInheritedScenario(&ft, gherkinFeature, ruleBackgrounds, ruleChild.GetScenario(), status)
+				}
+			}
+		}
+
+		if child.GetScenario() != nil {
+			formatInheritedScenario(&ft, gherkinFeature, backgrounds, child.GetScenario(), status)
+		}
 	}
 	return ft
 }
 
+// formatInheritedScenario adds the scenario (or the rows of the scenario outline's examples) to the feature.
+// Scenarios inherit the feature's tags and begin with the steps of the backgrounds.
+func formatInheritedScenario(ft *Feature, gherkinFeature *msgs.GherkinDocument_Feature,
+	backgrounds []*msgs.GherkinDocument_Feature_Background, gherkinScenario *msgs.GherkinDocument_Feature_Scenario, status string) {
+	var backgroundSteps []Step
+	for _, background := range backgrounds {
+		backgroundSteps = append(backgroundSteps, FormatSteps(background.GetSteps(), status)...)
+	}
+
+	if len(gherkinScenario.GetExamples()) == 0 {
+		sc := FormatScenarioWithSteps(gherkinScenario, status)
+		sc.Tags = append(FormatTags(gherkinFeature.GetTags()), sc.Tags...)
+		sc.Steps = append(backgroundSteps, sc.Steps...)
+
+		ft.AddScenario(sc)
+
+		return
+	}
+
+	for _, example := range gherkinScenario.GetExamples() {
+		var placeholders []string
+		for _, cell := range example.GetTableHeader().GetCells() {
+			placeholders = append(placeholders, "<"+cell.GetValue()+">")
+		}
+
+		for _, row := range example.GetTableBody() {
+			var values []string
+			for _, cell := range row.GetCells() {
+				values = append(values, cell.GetValue())
+			}
+
+			sc := FormatScenarioWithSteps(gherkinScenario, status)
+			sc.Id = fmt.Sprintf("%s;%s", gherkinScenario.GetId(), row.GetId())
+			sc.Name = replacePlaceholders(sc.Name, placeholders, values)
+			sc.Tags = append(append(FormatTags(gherkinFeature.GetTags()), sc.Tags...), FormatTags(example.GetTags())...)
+
+			for i := range sc.Steps {
+				replaceStepPlaceholders(&sc.Steps[i], placeholders, values)
+			}
+
+			sc.Steps = append(append([]Step{}, backgroundSteps...), sc.Steps...)
+
+			ft.AddScenario(sc)
+		}
+	}
+}
+
+// replaceStepPlaceholders replaces placeholders in the step's text, data table and doc string
+// with values from the example's row
+func replaceStepPlaceholders(step *Step, placeholders, values []string) {
+	step.Name = replacePlaceholders(step.Name, placeholders, values)
+
+	for _, row := range step.Rows {
+		for i, cell := range row.Cells {
+			row.Cells[i] = replacePlaceholders(cell, placeholders, values)
+		}
+	}
+
+	if step.DocString != nil {
+		step.DocString.Value = replacePlaceholders(step.DocString.Value, placeholders, values)
+	}
+}
+
+func replacePlaceholders(text string, placeholders, values []string) string {
+	for i, placeholder := range placeholders {
+		text = strings.Replace(text, placeholder, values[i], -1)
+	}
+
+	return text
+}
+
 func FormatFeature(gherkinFeature *msgs.GherkinDocument_Feature) Feature {
 	ft := GenerateFeature(gherkinFeature.GetName(), gherkinFeature.GetName(), gherkinFeature.GetDescription(), int(gherkinFeature.Location.GetLine()))
+	ft.Keyword = gherkinFeature.GetKeyword()
 	return ft
 }
 
@@ -22,7 +125,7 @@ func FormatScenario(gherkinScenario *msgs.GherkinDocument_Feature_Scenario) Scen
 	sc := Scenario{
 		Steps: nil, Tags: FormatTags(gherkinScenario.GetTags()),
 		Id:          gherkinScenario.GetId(),
-		Keyword:     "scenario",
+		Keyword:     gherkinScenario.GetKeyword(),
 		Name:        gherkinScenario.GetName(),
 		Description: gherkinScenario.GetDescription(),
 		Type:        "scenario",
@@ -34,18 +137,45 @@ func FormatScenarioWithSteps(gherkinScenario *msgs.GherkinDocument_Feature_Scena
 	sc := Scenario{
 		Steps: nil, Tags: FormatTags(gherkinScenario.GetTags()),
 		Id:          gherkinScenario.GetId(),
-		Keyword:     "scenario",
+		Keyword:     gherkinScenario.GetKeyword(),
 		Name:        gherkinScenario.GetName(),
 		Description: gherkinScenario.GetDescription(),
 		Type:        "scenario",
 	}
 
-	for _, steps := range gherkinScenario.Steps {
-		sc.AddStep(steps.GetKeyword(), steps.GetText(), int(steps.Location.GetLine()), "", stepstatus)
-	}
+	sc.Steps = FormatSteps(gherkinScenario.Steps, stepstatus)
 	return sc
 }
 
+func FormatSteps(gherkinSteps []*msgs.GherkinDocument_Feature_Step, stepstatus string) []Step {
+	var steps []Step
+
+	for _, step := range gherkinSteps {
+		formattedstep := GenerateStep(step.GetKeyword(), step.GetText(), int(step.Location.GetLine()), "")
+		formattedstep.StepResult.RunStatus = stepstatus
+		formattedstep.Rows = FormatDataTable(step.GetDataTable())
+		formattedstep.DocString = FormatDocString(step.GetDocString())
+		steps = append(steps, formattedstep)
+	}
+
+	return steps
+}
+
+func FormatDataTable(gherkinTable *msgs.GherkinDocument_Feature_Step_DataTable) []Row {
+	var rows []Row
+
+	for _, row := range gherkinTable.GetRows() {
+		var cells []string
+		for _, cell := range row.GetCells() {
+			cells = append(cells, cell.GetValue())
+		}
+
+		rows = append(rows, Row{Cells: cells})
+	}
+
+	return rows
+}
+
 func FormatTags(cucumberTags []*msgs.GherkinDocument_Feature_Tag) []Tag {
 	var tags []Tag
 
@@ -55,3 +185,15 @@ func FormatTags(cucumberTags []*msgs.GherkinDocument_Feature_Tag) []Tag {
 
 	return tags
 }
+
+func FormatDocString(gherkinDocString *msgs.GherkinDocument_Feature_Step_DocString) *DocString {
+	if gherkinDocString == nil {
+		return nil
+	}
+
+	return &DocString{
+		Value:       gherkinDocString.GetContent(),
+		ContentType: gherkinDocString.GetMediaType(),
+		Linenumber:  int(gherkinDocString.GetLocation().GetLine()),
+	}
+}
@@ -27,10 +27,22 @@ type Tag struct {
 
 type Step struct {
 	StepResult Stepresult   `json:"result"`
-	match      Filelocation `json:"match"`
+	Match      Filelocation `json:"match"`
 	Keyword    string       `json:"keyword"`
 	Name       string       `json:"name"`
 	Line       int          `json:"line"`
+	Rows       []Row        `json:"rows,omitempty"`
+	DocString  *DocString   `json:"doc_string,omitempty"`
+}
+
+type Row struct {
+	Cells []string `json:"cells"`
+}
+
+type DocString struct {
+	Value       string `json:"value"`
+	ContentType string `json:"content_type,omitempty"`
+	Linenumber  int    `json:"line"`
 }
 
 type Stepresult struct {
@@ -40,7 +52,7 @@ type Stepresult struct {
 }
 
 type Filelocation struct {
-	Location string `json:"location"`
+	Location string `json:"location,omitempty"`
 }
 
 func GenerateScenario() Scenario {
@@ -66,7 +78,7 @@ func (f *Feature) AddScenario(sc Scenario) {
 func GenerateStep(keyword string, name string, line int, location string) Step {
 	newstep := Step{
 		StepResult: Stepresult{},
-		match:      Filelocation{Location: location},
+		Match:      Filelocation{Location: location},
 		Keyword:    keyword,
 		Name:       name,
 		Line:       line,
@@ -83,7 +95,7 @@ func (sc *Scenario) AddStep(keyword string, name string, line int, location stri
 		StepResult: Stepresult{
 			RunStatus: result,
 		},
-		match:   Filelocation{Location: location},
+		Match:   Filelocation{Location: location},
 		Keyword: keyword,
 		Name:    name,
 		Line:    line,
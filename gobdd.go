@@ -2,18 +2,23 @@ package gobdd
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/anuragh27crony/gobdd/formatter/cucumber"
+	"io"
 	"io/ioutil"
 	"log"
+	"math/big"
 	"os"
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -28,20 +33,42 @@ type Suite struct {
 	options        SuiteOptions
 	hasStepErrors  bool
 	parameterTypes map[string][]string
+	transformers   map[string]reflect.Value
 	reportpath     string
 	generatereport bool
+	tagFilter      tagExpression
+	featureFile    string
+	featureName    string
+	dialect        *gherkin.GherkinDialect
+	undefinedSteps []string
+	undefinedLines []string
+	stopped        bool
+	workers        chan struct{}
+	serial         sync.RWMutex
+	mu             sync.Mutex
+	// out receives the summary of undefined steps, it's printed even without the -v flag
+	out io.Writer
 }
 
 // SuiteOptions holds all the information about how the suite or features/steps should be configured
 type SuiteOptions struct {
 	featuresPaths  string
+	lines          []int64
 	ignoreTags     []string
 	tags           []string
+	tagExpression  string
 	beforeScenario []func(ctx Context)
 	afterScenario  []func(ctx Context)
 	beforeStep     []func(ctx Context)
 	afterStep      []func(ctx Context)
 	runInParallel  bool
+	language       string
+	warnAmbiguous  bool
+	strictKeywords bool
+	dryRun         bool
+	strict         bool
+	failFast       bool
+	concurrency    int
 }
 
 // NewSuiteOptions creates a new suite configuration with default values
@@ -54,21 +81,111 @@ func NewSuiteOptions() SuiteOptions {
 		afterScenario:  []func(ctx Context){},
 		beforeStep:     []func(ctx Context){},
 		afterStep:      []func(ctx Context){},
+		language:       gherkin.DEFAULT_DIALECT,
 	}
 }
 
-// RunInParallel runs tests in parallel
+// RunInParallel runs the suite's test in parallel to other tests.
+// Scenarios of the suite run one after another unless WithConcurrency is used.
 func RunInParallel() func(*SuiteOptions) {
 	return func(options *SuiteOptions) {
 		options.runInParallel = true
 	}
 }
 
+// SerialTag marks scenarios which shouldn't run in parallel to other scenarios
+const SerialTag = "@serial"
+
+// WithConcurrency runs up to n scenarios (and rows of scenario outlines) at the same time.
+// Scenarios of a feature (including scenarios of rules) run in parallel to each other (features run one after another),
+// scenarios tagged with @serial run alone. Results in the report keep the order of the feature file.
+// Step functions and hooks have to be safe for concurrent use.
+func WithConcurrency(n int) func(*SuiteOptions) {
+	return func(options *SuiteOptions) {
+		options.concurrency = n
+	}
+}
+
+// WithAmbiguousStepsWarning makes ambiguous steps (matching more than one step definition) log a warning
+// and run the first registered definition instead of failing
+func WithAmbiguousStepsWarning() func(*SuiteOptions) {
+	return func(options *SuiteOptions) {
+		options.warnAmbiguous = true
+	}
+}
+
+// WithStrictKeywords makes steps fail when they match only step definitions added for another keyword,
+// for example a `Then` step matching a definition added with AddGiven
+func WithStrictKeywords() func(*SuiteOptions) {
+	return func(options *SuiteOptions) {
+		options.strictKeywords = true
+	}
+}
+
+// WithStrict makes pending steps (returning ErrPending) and undefined steps (not matching any step definition)
+// fail the suite. Without it, such steps are only reported with the pending or undefined status.
+func WithStrict() func(*SuiteOptions) {
+	return func(options *SuiteOptions) {
+		options.strict = true
+	}
+}
+
+// WithFailFast stops the suite after the first failed scenario. Remaining scenarios and features
+// aren't started and are reported as skipped.
+func WithFailFast() func(*SuiteOptions) {
+	return func(options *SuiteOptions) {
+		options.failFast = true
+	}
+}
+
+// WithDryRun makes the suite match every step with its step definition and check the step's arguments
+// without calling step functions or hooks. Undefined, ambiguous steps and steps with arguments
+// which cannot be passed to the step function fail, other steps are reported as skipped.
+func WithDryRun() func(*SuiteOptions) {
+	return func(options *SuiteOptions) {
+		options.dryRun = true
+	}
+}
+
 // WithFeaturesPath configures a pattern (regexp) where feature can be found.
 // The default value is "features/*.feature"
 func WithFeaturesPath(path string) func(*SuiteOptions) {
 	return func(options *SuiteOptions) {
-		options.featuresPaths = path
+		options.featuresPaths, options.lines = splitFeaturesPath(path)
+	}
+}
+
+// FeaturesPathEnv is the name of the environment variable which overrides the path configured by WithFeaturesPath
+const FeaturesPathEnv = "GOBDD_FEATURES_PATH"
+
+// splitFeaturesPath splits the locator like `features/x.feature:12:20` into the path and line numbers
+func splitFeaturesPath(path string) (string, []int64) {
+	var lines []int64
+
+	for {
+		i := strings.LastIndex(path, ":")
+		if i < 0 {
+			break
+		}
+
+		line, err := strconv.ParseInt(path[i+1:], 10, 64)
+		if err != nil || line <= 0 {
+			break
+		}
+
+		lines = append([]int64{line}, lines...)
+		path = path[:i]
+	}
+
+	return path, lines
+}
+
+// WithLanguage configures the default language (like "de" or "pl") of feature files.
+// Files with the `# language:` header use the language from the header.
+// The default value is "en"
+func WithLanguage(language string) func(*SuiteOptions) {
+	return func(options *SuiteOptions) {
+		options.language = language
 	}
 }
 
@@ -80,6 +197,17 @@ func WithTags(tags []string) func(*SuiteOptions) {
 	}
 }
 
+// WithTagExpression configures which scenarios should be run using a Cucumber tag expression, for example
+//
+// 	WithTagExpression("(@smoke or @critical) and not @wip")
+//
+// The expression is combined with tags configured by WithTags and WithIgnoredTags.
+func WithTagExpression(expr string) func(*SuiteOptions) {
+	return func(options *SuiteOptions) {
+		options.tagExpression = expr
+	}
+}
+
 // WithBeforeScenario configures functions that should be executed before every scenario
 func WithBeforeScenario(f func(ctx Context)) func(*SuiteOptions) {
 	return func(options *SuiteOptions) {
@@ -117,8 +245,33 @@ func WithIgnoredTags(tags []string) func(*SuiteOptions) {
 }
 
 type stepDef struct {
-	expr *regexp.Regexp
-	f    interface{}
+	expr     *regexp.Regexp
+	params   []expressionParam
+	f        interface{}
+	keyword  stepKeyword
+	source   string
+	location string
+	function string
+}
+
+// origin describes where the step definition was added, like `/src/steps_test.go:12 (steps.addPizzas)`
+func (def stepDef) origin() string {
+	return fmt.Sprintf("%s (%s)", def.location, def.function)
+}
+
+// arguments returns values of the parameters captured from the step's text
+func (def stepDef) arguments(text string) [][]byte {
+	match := def.expr.FindSubmatch([]byte(text))
+	if def.params == nil {
+		return match[1:]
+	}
+
+	args := make([][]byte, 0, len(def.params))
+	for _, param := range def.params {
+		args = append(args, param.value(match))
+	}
+
+	return args
 }
 
 type StepTest interface {
@@ -142,6 +295,28 @@ type TestingT interface {
 // TestingTKey is used to store reference to current *testing.T instance
 type TestingTKey struct{}
 
+// TagsKey is used to store names ([]string) of the current scenario's tags, including inherited ones
+type TagsKey struct{}
+
+// ScenarioInfoKey is used to store the ScenarioInfo of the current scenario
+type ScenarioInfoKey struct{}
+
+// stdContextKey is used to store the context.Context of the current scenario
+type stdContextKey struct{}
+
+// ScenarioInfo describes the scenario which is being executed.
+// A step function receives it when it has a parameter of the ScenarioInfo type.
+type ScenarioInfo struct {
+	FeatureFile string
+	FeatureName string
+	// Name of the scenario. Placeholders in names of scenario outlines are replaced with the example's values
+	Name string
+	// Line of the scenario or the example's row
+	Line int
+	// Tags of the scenario, including inherited ones
+	Tags []string
+}
+
 // Creates a new suites with given configuration and empty steps defined
 func NewSuite(t TestingT, optionClosures ...func(*SuiteOptions)) *Suite {
 	options := NewSuiteOptions()
@@ -150,17 +325,48 @@ func NewSuite(t TestingT, optionClosures ...func(*SuiteOptions)) *Suite {
 		optionClosures[i](&options)
 	}
 
+	if path := os.Getenv(FeaturesPathEnv); path != "" {
+		WithFeaturesPath(path)(&options)
+	}
+
+	if gherkin.GherkinDialectsBuildin().GetDialect(options.language) == nil {
+		t.Fatalf("the language %s is not supported", options.language)
+	}
+
+	tagFilter, err := newTagFilter(options)
+	if err != nil {
+		t.Fatalf("%s", err)
+		tagFilter = tagTrue{}
+	}
+
 	s := &Suite{
 		t:              t,
 		steps:          []stepDef{},
 		options:        options,
 		parameterTypes: map[string][]string{},
+		transformers:   map[string]reflect.Value{},
+		tagFilter:      tagFilter,
+		out:            os.Stdout,
+	}
+
+	if options.concurrency > 1 {
+		s.workers = make(chan struct{}, options.concurrency)
 	}
 
-	s.AddParameterTypes(`{int}`, []string{`(\d)`})
-	s.AddParameterTypes(`{float}`, []string{`([-+]?\d*\.?\d*)`})
-	s.AddParameterTypes(`{word}`, []string{`([\d\w]+)`})
-	s.AddParameterTypes(`{text}`, []string{`"([\d\w\-\s]+)"`, `'([\d\w\-\s]+)'`})
+	s.AddParameterTypes(`{int}`, []string{`-?\d+`})
+	s.AddParameterTypes(`{float}`, []string{floatRegexp})
+	s.AddParameterTypeWithTransformer(`{bigdecimal}`, []string{floatRegexp}, func(value string) (*big.Float, error) {
+		f, ok := new(big.Float).SetString(value)
+		if !ok {
+			return nil, fmt.Errorf("%s is not a decimal number", value)
+		}
+
+		return f, nil
+	})
+	s.AddParameterTypes(`{word}`, []string{`[^\s]+`})
+	s.AddParameterTypes(`{string}`, stringRegexps)
+	s.AddParameterTypes(`{text}`, stringRegexps)
+	s.AddParameterTypes(`{}`, []string{`.*`})
 
 	return s
 }
@@ -175,9 +381,10 @@ func (s *Suite) WithJsonReport(filepath string) {
 // The first argument is the parameter type and the second parameter is a list of regular expressions
 // that should replace the parameter type.
 //
-//    s.AddParameterTypes(`{int}`, []string{`(\d)`})
+//    s.AddParameterTypes(`{color}`, []string{`red|green|blue`})
 //
 // The regular expression should compile, otherwise will produce an error and stop executing.
+// If the regular expression has groups, the parameter's value is taken from the first matching group.
 func (s *Suite) AddParameterTypes(from string, to []string) {
 	for _, to := range to {
 		_, err := regexp.Compile(to)
@@ -189,55 +396,134 @@ func (s *Suite) AddParameterTypes(from string, to []string) {
 	}
 }
 
+// AddParameterTypeWithTransformer adds a parameter type with a transformer function converting the matched text into a value.
+// The transformer has to be a function like func(string) (T, error). A step function
+// receives the value returned by the transformer as the parameter of type T.
+//
+// 	s.AddParameterTypeWithTransformer(`{color}`, []string{`red|green|blue`}, func(value string) (Color, error) {
+// 		return Color(value), nil
+// 	})
+//
+// The error returned by the transformer fails the step.
+func (s *Suite) AddParameterTypeWithTransformer(from string, to []string, transformer interface{}) {
+	if err := validateTransformer(transformer); err != nil {
+		s.t.Fatalf("the transformer for key %s is incorrect: %s", from, err)
+
+		return
+	}
+
+	s.AddParameterTypes(from, to)
+	s.transformers[from] = reflect.ValueOf(transformer)
+}
+
 // AddStep registers a step in the suite.
 //
+// The first parameter is a Cucumber expression, like `I have {int} cucumber(s) in my belly/stomach`.
+// Expressions anchored with ^ or $, or using groups of regular expressions like `(\d+)`,
+// are treated as regular expressions.
+//
 // The second parameter is the step function that gets executed
-// when a step definition matches the provided regular expression.
+// when a step definition matches the provided expression.
 //
-// A step function can have any number of parameters (even zero),
-// but it MUST accept a gobdd.StepTest and gobdd.Context as the first parameters (if there is any):
+// Parameters of the step function are filled by their types, in any order:
+// gobdd.StepTest, *testing.T, gobdd.Context, context.Context, gobdd.ScenarioInfo,
+// gobdd.DataTable and gobdd.DocString. The remaining parameters receive values
+// captured from the step, in order:
 //
 // 	func myStepFunction(t gobdd.StepTest, ctx gobdd.Context, first int, second int) {
 // 	}
+//
+// 	func myOtherStepFunction(first int, ctx context.Context, table gobdd.DataTable) error {
+// 	}
 func (s *Suite) AddStep(expr string, step interface{}) {
+	s.addStep(anyKeyword, expr, step, callerLocation(1))
+}
+
+// AddGiven registers a step in the suite like AddStep, but the step matches only Given steps
+// (and And/But steps following Given) unless no other step definition matches.
+// With the WithStrictKeywords option the step never matches steps with other keywords.
+func (s *Suite) AddGiven(expr string, step interface{}) {
+	s.addStep(givenKeyword, expr, step, callerLocation(1))
+}
+
+// AddWhen registers a step in the suite like AddStep, but the step matches only When steps
+// (and And/But steps following When) unless no other step definition matches.
+// With the WithStrictKeywords option the step never matches steps with other keywords.
+func (s *Suite) AddWhen(expr string, step interface{}) {
+	s.addStep(whenKeyword, expr, step, callerLocation(1))
+}
+
+// AddThen registers a step in the suite like AddStep, but the step matches only Then steps
+// (and And/But steps following Then) unless no other step definition matches.
+// With the WithStrictKeywords option the step never matches steps with other keywords.
+func (s *Suite) AddThen(expr string, step interface{}) {
+	s.addStep(thenKeyword, expr, step, callerLocation(1))
+}
+
+func (s *Suite) addStep(keyword stepKeyword, expr string, step interface{}, location string) {
 	err := validateStepFunc(step)
 	if err != nil {
-		s.t.Errorf("the step function for step `%s` is incorrect: %w", expr, err)
+		s.t.Errorf("the step function for step `%s` is incorrect: %s", expr, err)
 		s.hasStepErrors = true
 
 		return
 	}
 
-	exprs := s.applyParameterTypes(expr)
-
-	for _, expr := range exprs {
+	if looksLikeRegexp(expr) {
 		compiled, err := regexp.Compile(expr)
 		if err != nil {
-			s.t.Errorf("the step function is incorrect: %w", err)
+			s.t.Errorf("the step function is incorrect: %s", err)
 			s.hasStepErrors = true
 
 			return
 		}
 
-		s.steps = append(s.steps, stepDef{
-			expr: compiled,
-			f:    step,
+		s.registerStep(stepDef{
+			expr:     compiled,
+			f:        step,
+			keyword:  keyword,
+			source:   expr,
+			location: location,
 		})
+
+		return
+	}
+
+	compiled, params, err := compileExpression(expr, s.parameterTypes)
+	if err != nil {
+		s.t.Errorf("the step is incorrect: %s", err)
+		s.hasStepErrors = true
+
+		return
 	}
+
+	for i := range params {
+		params[i].transformer = s.transformers["{"+params[i].name+"}"]
+	}
+
+	s.registerStep(stepDef{
+		expr:     compiled,
+		params:   params,
+		f:        step,
+		keyword:  keyword,
+		source:   expr,
+		location: location,
+	})
 }
 
-func (s *Suite) applyParameterTypes(expr string) []string {
-	exprs := []string{expr}
+// registerStep adds the step definition to the suite if values captured by its expression
+// can be passed to the step function
+func (s *Suite) registerStep(def stepDef) {
+	def.function = functionName(def.f)
 
-	for from, to := range s.parameterTypes {
-		for _, t := range to {
-			if strings.Contains(expr, from) {
-				exprs = append(exprs, strings.Replace(expr, from, t, -1))
-			}
-		}
+	if err := validateStepParams(def); err != nil {
+		s.t.Errorf("the step function for step `%s` is incorrect: %s", def.source, err)
+		s.hasStepErrors = true
+
+		return
 	}
 
-	return exprs
+	s.steps = append(s.steps, def)
 }
 
 // AddRegexStep registers a step in the suite.
@@ -245,26 +531,47 @@ func (s *Suite) applyParameterTypes(expr string) []string {
 // The second parameter is the step function that gets executed
 // when a step definition matches the provided regular expression.
 //
-// A step function can have any number of parameters (even zero),
-// but it MUST accept a gobdd.StepTest and gobdd.Context as the first parameters (if there is any):
-//
-// 	func myStepFunction(t gobdd.StepTest, ctx gobdd.Context, first int, second int) {
-// 	}
+// The step function's parameters are filled like in AddStep.
 func (s *Suite) AddRegexStep(expr *regexp.Regexp, step interface{}) {
 	err := validateStepFunc(step)
 	if err != nil {
-		s.t.Errorf("the step function is incorrect: %w", err)
+		s.t.Errorf("the step function is incorrect: %s", err)
 		s.hasStepErrors = true
 
 		return
 	}
 
-	s.steps = append(s.steps, stepDef{
-		expr: expr,
-		f:    step,
+	s.registerStep(stepDef{
+		expr:     expr,
+		f:        step,
+		source:   expr.String(),
+		location: callerLocation(1),
 	})
 }
 
+// callerLocation returns the file:line of the function which called the function calling callerLocation.
+// The skip argument is the number of additional frames to skip.
+func callerLocation(skip int) string {
+	_, file, line, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return "unknown"
+	}
+
+	return fmt.Sprintf("%s:%d", file, line)
+}
+
+// functionName returns the name of the function prefixed with its package's name, like `steps.addPizzas`
+func functionName(f interface{}) string {
+	fn := runtime.FuncForPC(reflect.ValueOf(f).Pointer())
+	if fn == nil {
+		return "unknown"
+	}
+
+	name := fn.Name()
+
+	return name[strings.LastIndex(name, "/")+1:]
+}
+
 // Executes the suite with given options and defined steps
 func (s *Suite) Run() {
 	if s.hasStepErrors {
@@ -295,6 +602,17 @@ func (s *Suite) Run() {
 		writeJsonFile(s.reportpath, features)
 	}
 
+	s.printUndefinedSteps()
+}
+
+// printUndefinedSteps prints the undefined steps and snippets of their step definitions
+func (s *Suite) printUndefinedSteps() {
+	if len(s.undefinedSteps) == 0 {
+		return
+	}
+
+	fmt.Fprintf(s.out, "Undefined steps:\n\t%s\n\nYou can implement undefined steps with these snippets:\n\n%s\n\n",
+		strings.Join(s.undefinedLines, "\n\t"), strings.Join(s.undefinedSteps, "\n\n"))
 }
 
 func (s *Suite) executeFeature(file string) (cucumber.Feature, error) {
@@ -305,7 +623,7 @@ func (s *Suite) executeFeature(file string) (cucumber.Feature, error) {
 	defer f.Close()
 	fileIO := bufio.NewReader(f)
 
-	doc, err := gherkin.ParseGherkinDocument(fileIO, (&msgs.Incrementing{}).NewId)
+	doc, err := gherkin.ParseGherkinDocumentForLanguage(fileIO, s.options.language, (&msgs.Incrementing{}).NewId)
 	if err != nil {
 		s.t.Fatalf("error while loading document: %s\n", err)
 	}
@@ -314,6 +632,9 @@ func (s *Suite) executeFeature(file string) (cucumber.Feature, error) {
 		return cucumber.Feature{}, nil
 	}
 
+	s.featureFile = file
+	s.featureName = doc.Feature.GetName()
+	s.dialect = gherkin.GherkinDialectsBuildin().GetDialect(doc.Feature.GetLanguage())
 	formattedFeature, err := s.runFeature(doc.Feature)
 
 	return formattedFeature, err
@@ -334,11 +655,14 @@ func writeJsonFile(jsonFilePath string, data interface{}) {
 }
 
 func (s *Suite) runFeature(feature *msgs.GherkinDocument_Feature) (cucumber.Feature, error) {
-	for _, tag := range feature.GetTags() {
-		if contains(s.options.ignoreTags, tag.Name) {
-			s.t.Logf("the feature (%s) is ignored ", feature.GetName())
-			return cucumber.FormatFeatureWithScenario(feature), nil
-		}
+	if s.skipFeature(feature) {
+		s.t.Logf("the feature (%s) is ignored ", feature.GetName())
+		return cucumber.FormatFeatureWithScenario(feature), nil
+	}
+
+	if s.isStopped() {
+		s.t.Logf("the feature (%s) is skipped after a failed scenario", feature.GetName())
+		return cucumber.FormatFeatureWithStatus(feature, "skipped"), nil
 	}
 
 	log.SetOutput(ioutil.Discard)
@@ -348,104 +672,240 @@ func (s *Suite) runFeature(feature *msgs.GherkinDocument_Feature) (cucumber.Feat
 	//TODO: ADD Report Formatted Feature Object to Context
 	formattedFeature := cucumber.FormatFeature(feature)
 
+	// scenarios running in parallel fill their results when the feature's test finishes
+	var results []scenarioResults
+
 	s.t.Run(fmt.Sprintf("%s %s", strings.TrimSpace(feature.Keyword), feature.Name), func(t *testing.T) {
-		var bkgSteps *msgs.GherkinDocument_Feature_Background
+		var bkgs []*msgs.GherkinDocument_Feature_Background
 
 		for _, child := range feature.Children {
 			if child.GetBackground() != nil {
-				bkgSteps = child.GetBackground()
+				bkgs = append(bkgs, child.GetBackground())
 			}
 
-			scenario := child.GetScenario()
-
-			if scenario == nil {
+			if rule := child.GetRule(); rule != nil {
+				results = append(results, s.runRule(rule, bkgs, feature.GetTags(), t))
 				continue
 			}
-			ctx := NewContext()
 
-			if s.skipScenario(scenario.GetTags()) {
-				//TODO: ADD SKIPPED SCENARIOS to Report Formatted Feature Object
-				formattedFeature.AddScenario(cucumber.FormatScenarioWithSteps(scenario, "skipped"))
-				t.Log(fmt.Sprintf("Skipping scenario %s", scenario.Name))
+			scenario := child.GetScenario()
+
+			if scenario == nil {
 				continue
 			}
 
-			formattedscenario := s.runScenario(ctx, scenario, bkgSteps, t)
-			formattedFeature.AddScenario(formattedscenario)
-
+			results = append(results, s.runChildScenario(scenario, bkgs, feature.GetTags(), t))
 		}
 	})
 
+	for _, formattedscenario := range collectResults(results) {
+		formattedFeature.AddScenario(*formattedscenario)
+	}
+
 	if hasErrors {
 		return cucumber.Feature{}, errors.New("the feature contains errors")
 	}
 	return formattedFeature, nil
 }
 
-func (s *Suite) getOutlineStep(
-	steps []*msgs.GherkinDocument_Feature_Step,
-	examples []*msgs.GherkinDocument_Feature_Scenario_Examples) []*msgs.GherkinDocument_Feature_Step {
-	stepsList := make([][]*msgs.GherkinDocument_Feature_Step, len(steps))
+// scenarioResults returns formatted scenarios of a scenario, a rule or a scenario outline.
+// With the WithConcurrency option their tests run in parallel, so the results can be collected
+// only when the feature's test finishes.
+type scenarioResults func() []*cucumber.Scenario
+
+func collectResults(results []scenarioResults) []*cucumber.Scenario {
+	var formattedscenarios []*cucumber.Scenario
+
+	for _, result := range results {
+		formattedscenarios = append(formattedscenarios, result()...)
+	}
+
+	return formattedscenarios
+}
+
+// runRule runs scenarios grouped by the rule.
+// The rule's background steps are executed after the feature's ones.
+func (s *Suite) runRule(rule *msgs.GherkinDocument_Feature_FeatureChild_Rule,
+	featureBkgs []*msgs.GherkinDocument_Feature_Background, featureTags []*msgs.GherkinDocument_Feature_Tag,
+	t *testing.T) scenarioResults {
+	bkgs := append([]*msgs.GherkinDocument_Feature_Background{}, featureBkgs...)
+
+	var results []scenarioResults
+
+	t.Run(fmt.Sprintf("%s %s", strings.TrimSpace(rule.Keyword), rule.Name), func(t *testing.T) {
+		// scenarios of the rule share the workers with other scenarios of the feature
+		if s.workers != nil {
+			t.Parallel()
+		}
+
+		for _, child := range rule.Children {
+			if child.GetBackground() != nil {
+				bkgs = append(bkgs, child.GetBackground())
+			}
+
+			scenario := child.GetScenario()
 
-	for i, outlineStep := range steps {
-		for _, example := range examples {
-			stepsList[i] = append(stepsList[i], s.stepsFromExamples(outlineStep, example)...)
+			if scenario == nil {
+				continue
+			}
+
+			results = append(results, s.runChildScenario(scenario, bkgs, featureTags, t))
 		}
+	})
+
+	return func() []*cucumber.Scenario { return collectResults(results) }
+}
+
+// runChildScenario runs the scenario (or every row of the scenario outline) if it isn't skipped by tags or lines.
+// The scenario inherits tags of the feature (and the rule) it belongs to.
+func (s *Suite) runChildScenario(scenario *msgs.GherkinDocument_Feature_Scenario,
+	bkgs []*msgs.GherkinDocument_Feature_Background, inheritedTags []*msgs.GherkinDocument_Feature_Tag,
+	t *testing.T) scenarioResults {
+	tags := inheritTags(inheritedTags, scenario.GetTags())
+
+	if len(scenario.GetExamples()) > 0 {
+		return s.runScenarioOutline(scenario, bkgs, tags, t)
 	}
 
-	var newSteps []*msgs.GherkinDocument_Feature_Step
+	if s.skipScenario(tags) || !s.matchesLines(scenario.GetLocation()) || s.isStopped() {
+		t.Log(fmt.Sprintf("Skipping scenario %s", scenario.Name))
 
-	if len(stepsList) == 0 {
-		return newSteps
+		formattedscenario := cucumber.FormatScenario(scenario)
+		formattedscenario.Tags = cucumber.FormatTags(tags)
+		formattedscenario.Steps = s.skippedSteps(bkgs, scenario.GetSteps())
+
+		return func() []*cucumber.Scenario { return []*cucumber.Scenario{&formattedscenario} }
 	}
 
-	for ei := range examples {
-		for ci := range examples[ei].TableBody {
-			for si := range steps {
-				newSteps = append(newSteps, stepsList[si][ci])
+	name := fmt.Sprintf("%s %s", strings.TrimSpace(scenario.Keyword), scenario.Name)
+	formattedscenario := cucumber.FormatScenario(scenario)
+	formattedscenario.Tags = cucumber.FormatTags(tags)
+
+	ctx := s.newScenarioContext(scenario.GetName(), scenario.GetLocation(), tags)
+	s.runScenario(ctx, name, scenario.Steps, bkgs, &formattedscenario, t)
+
+	return func() []*cucumber.Scenario { return []*cucumber.Scenario{&formattedscenario} }
+}
+
+// runScenarioOutline runs every row of the outline's examples as a separate scenario.
+// Examples are filtered by tags independently, every row has tags of the outline and its examples.
+func (s *Suite) runScenarioOutline(scenario *msgs.GherkinDocument_Feature_Scenario,
+	bkgs []*msgs.GherkinDocument_Feature_Background, scenarioTags []*msgs.GherkinDocument_Feature_Tag,
+	t *testing.T) scenarioResults {
+	var formattedscenarios []*cucumber.Scenario
+
+	t.Run(fmt.Sprintf("%s %s", strings.TrimSpace(scenario.Keyword), scenario.Name), func(t *testing.T) {
+		// rows of the outline share the workers with other scenarios of the feature
+		if s.workers != nil {
+			t.Parallel()
+		}
+
+		for _, example := range scenario.GetExamples() {
+			placeholders := examplePlaceholders(example)
+			tags := inheritTags(scenarioTags, example.GetTags())
+			skippedByTags := s.skipScenario(tags)
+
+			if skippedByTags {
+				t.Log(fmt.Sprintf("Skipping examples %s of scenario %s", example.Name, scenario.Name))
+			}
+
+			for _, row := range example.GetTableBody() {
+				skipped := skippedByTags || !s.matchesLines(scenario.GetLocation(), example.GetLocation(), row.GetLocation()) || s.isStopped()
+
+				var values []string
+				for _, cell := range row.GetCells() {
+					values = append(values, cell.GetValue())
+				}
+
+				scenarioName := s.stepFromExample(scenario.Name, row, placeholders)
+				name := fmt.Sprintf("%s (%s)", scenarioName, strings.Join(values, ", "))
+				steps := s.stepsFromExample(scenario.GetSteps(), row, placeholders)
+
+				formattedscenario := cucumber.FormatScenario(scenario)
+				formattedscenario.Id = fmt.Sprintf("%s;%s", scenario.GetId(), row.GetId())
+				formattedscenario.Name = scenarioName
+				formattedscenario.Tags = cucumber.FormatTags(tags)
+
+				formattedscenarios = append(formattedscenarios, &formattedscenario)
+
+				if skipped {
+					formattedscenario.Steps = s.skippedSteps(bkgs, steps)
+					continue
+				}
+
+				ctx := s.newScenarioContext(scenarioName, row.GetLocation(), tags)
+				s.runScenario(ctx, name, steps, bkgs, &formattedscenario, t)
 			}
 		}
-	}
+	})
+
+	return func() []*cucumber.Scenario { return formattedscenarios }
+}
+
+// inheritTags returns inherited tags followed by the element's own tags
+func inheritTags(inherited, tags []*msgs.GherkinDocument_Feature_Tag) []*msgs.GherkinDocument_Feature_Tag {
+	result := make([]*msgs.GherkinDocument_Feature_Tag, 0, len(inherited)+len(tags))
+	result = append(result, inherited...)
 
-	return newSteps
+	return append(result, tags...)
 }
 
-func (s *Suite) stepsFromExamples(
-	sourceStep *msgs.GherkinDocument_Feature_Step,
-	example *msgs.GherkinDocument_Feature_Scenario_Examples) []*msgs.GherkinDocument_Feature_Step {
-	steps := []*msgs.GherkinDocument_Feature_Step{}
+// newScenarioContext creates a new context for the scenario holding names of the scenario's tags
+// and the ScenarioInfo
+func (s *Suite) newScenarioContext(name string, location *msgs.Location, tags []*msgs.GherkinDocument_Feature_Tag) Context {
+	ctx := NewContext()
+	names := tagNames(tags)
+
+	ctx.Set(TagsKey{}, names)
+	ctx.Set(ScenarioInfoKey{}, ScenarioInfo{
+		FeatureFile: s.featureFile,
+		FeatureName: s.featureName,
+		Name:        name,
+		Line:        int(location.GetLine()),
+		Tags:        names,
+	})
 
-	placeholders := example.GetTableHeader().GetCells()
-	placeholdersValues := []string{}
+	return ctx
+}
 
-	for _, placeholder := range placeholders {
-		ph := "<" + placeholder.GetValue() + ">"
-		placeholdersValues = append(placeholdersValues, ph)
+func tagNames(tags []*msgs.GherkinDocument_Feature_Tag) []string {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.GetName())
 	}
 
-	text := sourceStep.GetText()
+	return names
+}
 
-	for _, row := range example.GetTableBody() {
-		// iterate over the cells and update the text
-		stepText, expr := s.stepFromExample(text, row, placeholdersValues)
+// examplePlaceholders returns placeholders (like <name>) defined by the example's header
+func examplePlaceholders(example *msgs.GherkinDocument_Feature_Scenario_Examples) []string {
+	placeholders := []string{}
 
-		// find step definition for the new step
-		def, err := s.findStepDef(stepText)
-		if err != nil {
-			continue
-		}
+	for _, placeholder := range example.GetTableHeader().GetCells() {
+		placeholders = append(placeholders, "<"+placeholder.GetValue()+">")
+	}
+
+	return placeholders
+}
 
-		// add the step to the list
-		s.AddStep(expr, def.f)
+// stepsFromExample clones the outline's steps replacing placeholders with values from the example's row
+func (s *Suite) stepsFromExample(
+	sourceSteps []*msgs.GherkinDocument_Feature_Step,
+	row *msgs.GherkinDocument_Feature_TableRow, placeholders []string) []*msgs.GherkinDocument_Feature_Step {
+	steps := make([]*msgs.GherkinDocument_Feature_Step, 0, len(sourceSteps))
 
+	for _, sourceStep := range sourceSteps {
 		// clone a step
 		step := &msgs.GherkinDocument_Feature_Step{
 			Location: sourceStep.Location,
 			Keyword:  sourceStep.Keyword,
-			Text:     stepText,
+			Text:     s.stepFromExample(sourceStep.GetText(), row, placeholders),
 			Argument: sourceStep.Argument,
+			Id:       sourceStep.Id,
 		}
 
+		s.argumentFromExample(step, row, placeholders)
+
 		steps = append(steps, step)
 	}
 
@@ -454,16 +914,60 @@ func (s *Suite) stepsFromExamples(
 
 func (s *Suite) stepFromExample(
 	stepName string,
-	row *msgs.GherkinDocument_Feature_TableRow, placeholders []string) (string, string) {
-	expr := stepName
+	row *msgs.GherkinDocument_Feature_TableRow, placeholders []string) string {
+	return replacePlaceholders(stepName, row, placeholders)
+}
 
+// argumentFromExample replaces the step's data table or doc string with a copy
+// where placeholders are substituted with values from the example's row
+func (s *Suite) argumentFromExample(step *msgs.GherkinDocument_Feature_Step,
+	row *msgs.GherkinDocument_Feature_TableRow, placeholders []string) {
+	if table := step.GetDataTable(); table != nil {
+		rows := make([]*msgs.GherkinDocument_Feature_TableRow, 0, len(table.Rows))
+
+		for _, tableRow := range table.Rows {
+			cells := make([]*msgs.GherkinDocument_Feature_TableRow_TableCell, 0, len(tableRow.Cells))
+			for _, cell := range tableRow.Cells {
+				cells = append(cells, &msgs.GherkinDocument_Feature_TableRow_TableCell{
+					Location: cell.Location,
+					Value:    replacePlaceholders(cell.Value, row, placeholders),
+				})
+			}
+
+			rows = append(rows, &msgs.GherkinDocument_Feature_TableRow{
+				Location: tableRow.Location,
+				Cells:    cells,
+				Id:       tableRow.Id,
+			})
+		}
+
+		step.Argument = &msgs.GherkinDocument_Feature_Step_DataTable_{
+			DataTable: &msgs.GherkinDocument_Feature_Step_DataTable{
+				Location: table.Location,
+				Rows:     rows,
+			},
+		}
+	}
+
+	if docString := step.GetDocString(); docString != nil {
+		step.Argument = &msgs.GherkinDocument_Feature_Step_DocString_{
+			DocString: &msgs.GherkinDocument_Feature_Step_DocString{
+				Location:  docString.Location,
+				MediaType: replacePlaceholders(docString.MediaType, row, placeholders),
+				Content:   replacePlaceholders(docString.Content, row, placeholders),
+				Delimiter: docString.Delimiter,
+			},
+		}
+	}
+}
+
+// replacePlaceholders replaces every <placeholder> in the text with the value from the example's row
+func replacePlaceholders(text string, row *msgs.GherkinDocument_Feature_TableRow, placeholders []string) string {
 	for i, ph := range placeholders {
-		t := getRegexpForVar(row.Cells[i].Value)
-		expr = strings.Replace(expr, ph, t, -1)
-		stepName = strings.Replace(stepName, ph, row.Cells[i].Value, -1)
+		text = strings.Replace(text, ph, row.Cells[i].Value, -1)
 	}
 
-	return stepName, expr
+	return text
 }
 
 func (s *Suite) callBeforeScenarios(ctx Context) {
@@ -491,60 +995,179 @@ func (s *Suite) callAfterSteps(ctx Context) {
 	}
 }
 
-func (s *Suite) runScenario(ctx Context, scenario *msgs.GherkinDocument_Feature_Scenario,
-	bkg *msgs.GherkinDocument_Feature_Background, t *testing.T) cucumber.Scenario {
-	formattedscenario := cucumber.FormatScenario(scenario)
-	t.Run(fmt.Sprintf("%s %s", strings.TrimSpace(scenario.Keyword), scenario.Name), func(t *testing.T) {
+// runScenario runs the scenario's steps and stores their results in the formatted scenario.
+// With the WithConcurrency option the scenario runs in parallel to other scenarios of its feature
+// (unless it's tagged with @serial), so the results are complete only when the feature's test finishes.
+func (s *Suite) runScenario(ctx Context, name string, steps []*msgs.GherkinDocument_Feature_Step,
+	bkgs []*msgs.GherkinDocument_Feature_Background, formattedscenario *cucumber.Scenario, t *testing.T) {
+	t.Run(name, func(t *testing.T) {
+		if s.runsInParallel(ctx) {
+			t.Parallel()
+
+			s.workers <- struct{}{}
+			defer func() { <-s.workers }()
+
+			s.serial.RLock()
+			defer s.serial.RUnlock()
+		} else if s.workers != nil {
+			// a @serial scenario waits for running scenarios and blocks others until it finishes
+			s.serial.Lock()
+			defer s.serial.Unlock()
+		}
+
+		if s.isStopped() {
+			formattedscenario.Steps = s.skippedSteps(bkgs, steps)
+			t.Skip("the scenario is skipped after a failed scenario")
+		}
+
+		defer func() {
+			if t.Failed() && s.options.failFast {
+				s.stop()
+			}
+		}()
+
 		// NOTE consider passing t as argument to scenario hooks
 		ctx.Set(TestingTKey{}, t)
 		defer ctx.Set(TestingTKey{}, nil)
 
-		//TODO: ADD Report Formatted Scenario Object to FEATURE OBJECT fetched from Context
-
-		s.callBeforeScenarios(ctx)
-		defer s.callAfterScenarios(ctx)
+		stdCtx, cancel := context.WithCancel(context.Background())
+		ctx.Set(stdContextKey{}, stdCtx)
+		defer cancel()
 
-		if bkg != nil {
-			steps := s.getBackgroundSteps(bkg)
-			s.runSteps(ctx, t, steps, cucumber.Scenario{})
+		if !s.options.dryRun {
+			s.callBeforeScenarios(ctx)
+			defer s.callAfterScenarios(ctx)
 		}
-		steps := scenario.Steps
-		if examples := scenario.GetExamples(); len(examples) > 0 {
-			c := ctx.Clone()
-			steps = s.getOutlineStep(scenario.GetSteps(), examples)
-			formattedscenario = s.runSteps(c, t, steps, formattedscenario)
-		} else {
-			c := ctx.Clone()
-			formattedscenario = s.runSteps(c, t, steps, formattedscenario)
+
+		// the background steps are reported as the first steps of the scenario
+		skip := false
+		formatted := *formattedscenario
+
+		for _, bkg := range bkgs {
+			formatted, skip = s.runSteps(ctx, t, s.getBackgroundSteps(bkg), formatted, skip)
 		}
+
+		c := ctx.Clone()
+		*formattedscenario, _ = s.runSteps(c, t, steps, formatted, skip)
 	})
-	return formattedscenario
 }
 
-func (s *Suite) runSteps(ctx Context, t *testing.T, steps []*msgs.GherkinDocument_Feature_Step, formattedscenario cucumber.Scenario) cucumber.Scenario {
+// runsInParallel tells whether the scenario should run in parallel to other scenarios
+func (s *Suite) runsInParallel(ctx Context) bool {
+	tags, _ := ctx.Get(TagsKey{}, []string{})
+
+	return s.workers != nil && !contains(tags.([]string), SerialTag)
+}
+
+// skippedSteps formats the background steps followed by the scenario's steps as skipped steps
+func (s *Suite) skippedSteps(bkgs []*msgs.GherkinDocument_Feature_Background,
+	steps []*msgs.GherkinDocument_Feature_Step) []cucumber.Step {
+	var formattedsteps []cucumber.Step
+
+	for _, bkg := range bkgs {
+		formattedsteps = append(formattedsteps, cucumber.FormatSteps(s.getBackgroundSteps(bkg), "skipped")...)
+	}
+
+	return append(formattedsteps, cucumber.FormatSteps(steps, "skipped")...)
+}
+
+// stop makes the suite skip scenarios which haven't been started yet
+func (s *Suite) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stopped = true
+}
+
+func (s *Suite) isStopped() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.stopped
+}
+
+// runSteps runs the steps until one of them doesn't pass, the remaining steps are skipped.
+// If skip is true, all the steps are skipped. It returns true if the following steps should be skipped.
+func (s *Suite) runSteps(ctx Context, t *testing.T, steps []*msgs.GherkinDocument_Feature_Step,
+	formattedscenario cucumber.Scenario, skip bool) (cucumber.Scenario, bool) {
+	previous := anyKeyword
+
 	for _, step := range steps {
+		keyword := keywordType(s.dialect, step.GetKeyword())
+		if keyword == conjunctionKeyword {
+			keyword = previous
+		}
+
+		previous = keyword
+
 		formatStep(ctx)
-		formattedstep := s.runStep(ctx, t, step)
+
+		if skip {
+			formattedscenario.AddStepObj(s.skipStep(ctx, t, step, keyword))
+			continue
+		}
+
+		formattedstep := s.runStep(ctx, t, step, keyword)
 		formattedscenario.AddStepObj(formattedstep)
+
+		// the dry run checks every step
+		skip = !s.options.dryRun && formattedstep.StepResult.RunStatus != "passed"
+	}
+
+	return formattedscenario, skip
+}
+
+// skipStep reports the step which isn't executed because a previous step of the scenario didn't pass.
+// The step is still matched with step definitions, so undefined steps are reported (with their snippets)
+// even if they aren't executed.
+func (s *Suite) skipStep(ctx Context, t *testing.T, step *msgs.GherkinDocument_Feature_Step, keyword stepKeyword) cucumber.Step {
+	def, err := s.findStepDef(step.Text, keyword)
+	if err == errStepNotFound {
+		return s.undefinedStep(ctx, t, step)
 	}
-	return formattedscenario
+
+	t.Run(stepName(step), func(t *testing.T) {
+		t.Skip("a previous step didn't pass")
+	})
+
+	return generateFormattedStep(ctx, step, def.location, "skipped")
 }
 
-func (s *Suite) runStep(ctx Context, t *testing.T, step *msgs.GherkinDocument_Feature_Step) cucumber.Step {
+// stepName returns the name of the step's test
+func stepName(step *msgs.GherkinDocument_Feature_Step) string {
+	return fmt.Sprintf("%s %s", strings.TrimSpace(step.Keyword), step.Text)
+}
+
+func (s *Suite) runStep(ctx Context, t *testing.T, step *msgs.GherkinDocument_Feature_Step, keyword stepKeyword) cucumber.Step {
 	defer func() {
 		if r := recover(); r != nil {
 			t.Error(r)
 		}
 	}()
-	def, err := s.findStepDef(step.Text)
-	if err != nil {
-		t.Fatalf("cannot find step definition for step: %s%s", step.Keyword, step.Text)
+	def, err := s.findStepDef(step.Text, keyword)
+	if ambiguous, ok := err.(*ambiguousStepError); ok {
+		if !s.options.warnAmbiguous {
+			return s.failStep(ctx, t, step, "ambiguous", ambiguous)
+		}
+
+		t.Logf("WARNING: %s", ambiguous)
+	} else if wrongKeyword, ok := err.(*wrongKeywordError); ok {
+		return s.failStep(ctx, t, step, "failed", wrongKeyword)
+	} else if err != nil {
+		return s.undefinedStep(ctx, t, step)
 	}
 
 	var failed, skipped bool
+	var stepErr error
+
+	params := def.arguments(step.Text)
+	location := fmt.Sprintf("%s:%d", s.featureFile, step.GetLocation().GetLine())
 
-	params := def.expr.FindSubmatch([]byte(step.Text))[1:]
-	t.Run(fmt.Sprintf("%s %s", strings.TrimSpace(step.Keyword), step.Text), func(t *testing.T) {
+	if s.options.dryRun {
+		return s.dryRunStep(ctx, t, step, def, params, location)
+	}
+
+	t.Run(stepName(step), func(t *testing.T) {
 		// NOTE consider passing t as argument to step hooks
 		ctx.Set(TestingTKey{}, t)
 		defer ctx.Set(TestingTKey{}, nil)
@@ -555,34 +1178,115 @@ func (s *Suite) runStep(ctx Context, t *testing.T, step *msgs.GherkinDocument_Fe
 		s.callBeforeSteps(ctx)
 		defer s.callAfterSteps(ctx)
 		defer func() {
+			if t.Failed() {
+				t.Logf("the step is defined at %s", def.origin())
+			}
+
 			failed = t.Failed()
 			skipped = t.Skipped()
 			t.Logf("Step Data:  Duration- %v , <isFailed: %v <isSkipped: %v", 0, t.Failed(), t.Skipped())
 		}()
 
-		def.run(ctx, t, params)
-		//failed = t.Failed()
-		//skipped = t.Skipped()
+		stepErr = def.run(ctx, t, params, stepArgument(step), location)
+
+		switch {
+		case errors.Is(stepErr, ErrSkip):
+			t.Skip(stepErr)
+		case errors.Is(stepErr, ErrPending):
+			if s.options.strict {
+				t.Error(stepErr)
+			} else {
+				t.Log(stepErr)
+			}
+		}
 	})
 
-	return generateFormattedStep(ctx, step, failed, skipped)
+	status := "passed"
+
+	switch {
+	case errors.Is(stepErr, ErrPending):
+		status = "pending"
+	case skipped:
+		status = "skipped"
+	case failed:
+		status = "failed"
+	}
+
+	formattedstep := generateFormattedStep(ctx, step, def.location, status)
+
+	if stepErr != nil && !errors.Is(stepErr, ErrSkip) {
+		formattedstep.StepResult.ErrorMsg = stepErr.Error()
+	}
+
+	return formattedstep
 }
 
-func generateFormattedStep(ctx Context, step *msgs.GherkinDocument_Feature_Step, isfailed bool, isskipped bool) cucumber.Step {
-	start, _ := ctx.Get(time.Time{})
-	duration := time.Since(start.(time.Time))
+// dryRunStep checks if the step's arguments can be passed to the step function without calling it.
+// The step gets the skipped status or fails if the arguments are incorrect.
+func (s *Suite) dryRunStep(ctx Context, t *testing.T, step *msgs.GherkinDocument_Feature_Step, def stepDef,
+	params [][]byte, location string) cucumber.Step {
+	if _, err := def.callArguments(ctx, t, params, stepArgument(step)); err != nil {
+		formattedstep := s.failStep(ctx, t, step, "failed", fmt.Errorf("%s (%s)", err, location))
+		formattedstep.Match.Location = def.location
 
-	status := "passed"
+		return formattedstep
+	}
 
-	if isfailed {
-		status = "failed"
+	return generateFormattedStep(ctx, step, def.location, "skipped")
+}
+
+// failStep fails the step with the error and the status without running any step definition
+func (s *Suite) failStep(ctx Context, t *testing.T, step *msgs.GherkinDocument_Feature_Step, status string, err error) cucumber.Step {
+	t.Run(stepName(step), func(t *testing.T) {
+		t.Error(err)
+	})
+
+	formattedstep := generateFormattedStep(ctx, step, "", status)
+	formattedstep.StepResult.ErrorMsg = err.Error()
+
+	return formattedstep
+}
+
+// undefinedStep reports the step which doesn't match any step definition and remembers the snippet
+// of the step definition to print it at the end of the suite.
+// The step fails only in the strict mode (or the dry run).
+func (s *Suite) undefinedStep(ctx Context, t *testing.T, step *msgs.GherkinDocument_Feature_Step) cucumber.Step {
+	line := fmt.Sprintf("%s%s (%s:%d)", step.Keyword, step.Text, s.featureFile, step.GetLocation().GetLine())
+	err := fmt.Errorf("cannot find step definition for step: %s", line)
+
+	s.mu.Lock()
+	if snippet := stepSnippet(step); !contains(s.undefinedSteps, snippet) {
+		s.undefinedSteps = append(s.undefinedSteps, snippet)
 	}
 
-	if isskipped {
-		status = "skipped"
+	if !contains(s.undefinedLines, line) {
+		s.undefinedLines = append(s.undefinedLines, line)
+	}
+	s.mu.Unlock()
+
+	if s.options.strict || s.options.dryRun {
+		return s.failStep(ctx, t, step, "undefined", err)
 	}
 
-	formattedstep := cucumber.GenerateStep(step.GetKeyword(), step.GetText(), int(step.Location.GetLine()), "")
+	t.Run(stepName(step), func(t *testing.T) {
+		t.Log(err)
+	})
+
+	formattedstep := generateFormattedStep(ctx, step, "", "undefined")
+	formattedstep.StepResult.ErrorMsg = err.Error()
+
+	return formattedstep
+}
+
+// generateFormattedStep creates the step of the report with the status:
+// passed, failed, skipped, pending, undefined or ambiguous
+func generateFormattedStep(ctx Context, step *msgs.GherkinDocument_Feature_Step, location string, status string) cucumber.Step {
+	start, _ := ctx.Get(time.Time{})
+	duration := time.Since(start.(time.Time))
+
+	formattedstep := cucumber.GenerateStep(step.GetKeyword(), step.GetText(), int(step.Location.GetLine()), location)
+	formattedstep.Rows = cucumber.FormatDataTable(step.GetDataTable())
+	formattedstep.DocString = cucumber.FormatDocString(step.GetDocString())
 	formattedstep.UpdateResult(status, duration.Nanoseconds())
 	return formattedstep
 }
@@ -593,103 +1297,275 @@ func formatStep(ctx Context) {
 
 }
 
-func (def *stepDef) run(ctx Context, t TestingT, params [][]byte) { // nolint:interfacer
+// run calls the step function with the step's parameters. It returns the error returned by the function
+// (as the last value). Errors other than ErrPending and ErrSkip (or errors wrapping them) fail the step.
+// If the step's arguments cannot be passed to the function, the step fails and the error is returned.
+func (def *stepDef) run(ctx Context, t TestingT, params [][]byte, argument interface{}, location string) error { // nolint:interfacer
 	defer func() {
 		if r := recover(); r != nil {
 			t.Errorf("%+v", r)
 		}
 	}()
 
+	in, err := def.callArguments(ctx, t, params, argument)
+	if err != nil {
+		err = fmt.Errorf("%s (%s)", err, location)
+		t.Error(err)
+
+		return err
+	}
+
 	d := reflect.ValueOf(def.f)
-	if len(params)+2 != d.Type().NumIn() {
-		t.Fatalf("the step function %s accepts %d arguments but %d received", d.String(), d.Type().NumIn(), len(params)+2)
 
-		return
+	out := d.Call(in)
+	if len(out) == 0 || d.Type().Out(len(out)-1) != errorType || out[len(out)-1].IsNil() {
+		return nil
 	}
 
-	in := []reflect.Value{reflect.ValueOf(t), reflect.ValueOf(ctx)}
+	err = out[len(out)-1].Interface().(error)
+	if !errors.Is(err, ErrPending) && !errors.Is(err, ErrSkip) {
+		t.Error(err)
+	}
+
+	return err
+}
+
+// callArguments returns values of the step function's parameters: injected values and values
+// captured from the step converted to types of the parameters. The step's data table or doc string
+// has to be accepted by the function.
+func (def *stepDef) callArguments(ctx Context, t TestingT, params [][]byte, argument interface{}) ([]reflect.Value, error) {
+	d := reflect.ValueOf(def.f)
+	in := make([]reflect.Value, d.Type().NumIn())
+	// positions of parameters receiving values captured from the step
+	var captured []int
+
+	acceptsArgument := false
+
+	for i := 0; i < d.Type().NumIn(); i++ {
+		inType := d.Type().In(i)
+
+		if isStepArgumentType(inType) && argument == nil {
+			return nil, fmt.Errorf("the step function %s expects %s but the step doesn't have one", d.Type(), inType)
+		}
+
+		if isStepArgumentType(inType) && reflect.TypeOf(argument) != inType {
+			return nil, fmt.Errorf("the step function %s expects %s but the step has %s", d.Type(), inType, reflect.TypeOf(argument))
+		}
+
+		if isStepArgumentType(inType) {
+			acceptsArgument = true
+		}
+
+		if isInjectedType(inType) {
+			in[i] = injectedValue(inType, ctx, t, argument)
+		} else {
+			captured = append(captured, i)
+		}
+	}
+
+	if argument != nil && !acceptsArgument {
+		return nil, fmt.Errorf("the step has %s but the step function %s doesn't accept it", reflect.TypeOf(argument), d.Type())
+	}
+
+	if len(captured) != len(params) {
+		return nil, fmt.Errorf("the step function %s accepts %d parameters but %d were captured from the step",
+			d.String(), len(captured), len(params))
+	}
 
 	for i, v := range params {
-		if len(params) < i+1 {
-			break
+		inType := d.Type().In(captured[i])
+
+		if i < len(def.params) && def.params[i].transformer.IsValid() {
+			value, err := def.params[i].transform(v, inType)
+			if err != nil {
+				return nil, err
+			}
+
+			in[captured[i]] = value
+
+			continue
+		}
+
+		value, err := convertValue(string(v), inType)
+		if err != nil {
+			return nil, fmt.Errorf("cannot convert argument %d %q to %s: %v", i+1, v, inType, err)
 		}
 
-		inType := d.Type().In(i + 2)
-		paramType := paramType(v, inType)
-		in = append(in, paramType)
+		in[captured[i]] = value
 	}
 
-	d.Call(in)
+	return in, nil
 }
 
-func paramType(param []byte, inType reflect.Type) reflect.Value {
-	paramType := reflect.ValueOf(param)
-	if inType.Kind() == reflect.String {
-		paramType = reflect.ValueOf(string(paramType.Interface().([]uint8)))
+// stepArgument returns the argument (a data table or a doc string) placed below the step or nil if there is none
+func stepArgument(step *msgs.GherkinDocument_Feature_Step) interface{} {
+	if table := step.GetDataTable(); table != nil {
+		return newDataTable(table)
 	}
 
-	if inType.Kind() == reflect.Int {
-		s := paramType.Interface().([]uint8)
-		p, _ := strconv.Atoi(string(s))
-		paramType = reflect.ValueOf(p)
+	if docString := step.GetDocString(); docString != nil {
+		return newDocString(docString)
 	}
 
-	if inType.Kind() == reflect.Float32 {
-		s := paramType.Interface().([]uint8)
-		p, _ := strconv.ParseFloat(string(s), 32)
-		paramType = reflect.ValueOf(float32(p))
+	return nil
+}
+
+// ambiguousStepError is returned when more than one step definition matches the step
+type ambiguousStepError struct {
+	text string
+	defs []stepDef
+}
+
+func (e *ambiguousStepError) Error() string {
+	candidates := make([]string, 0, len(e.defs))
+	for _, def := range e.defs {
+		candidates = append(candidates, fmt.Sprintf("\t%s at %s", def.source, def.origin()))
 	}
 
-	if inType.Kind() == reflect.Float64 {
-		s := paramType.Interface().([]uint8)
-		p, _ := strconv.ParseFloat(string(s), 32)
-		paramType = reflect.ValueOf(p)
+	return fmt.Sprintf("the step \"%s\" is ambiguous, it matches:\n%s", e.text, strings.Join(candidates, "\n"))
+}
+
+// wrongKeywordError is returned in the strict keywords mode when the step matches
+// only step definitions added for other keywords
+type wrongKeywordError struct {
+	text    string
+	keyword stepKeyword
+	defs    []stepDef
+}
+
+func (e *wrongKeywordError) Error() string {
+	candidates := make([]string, 0, len(e.defs))
+	for _, def := range e.defs {
+		candidates = append(candidates, fmt.Sprintf("\t%s for %s at %s", def.source, def.keyword, def.origin()))
 	}
 
-	return paramType
+	return fmt.Sprintf("the step \"%s\" is used as %s but it matches only:\n%s", e.text, e.keyword, strings.Join(candidates, "\n"))
 }
 
-func (s *Suite) findStepDef(text string) (stepDef, error) {
-	var sd stepDef
+// errStepNotFound is returned by findStepDef when none of the step definitions matches the step
+var errStepNotFound = errors.New("cannot find step definition")
 
-	found := 0
+// findStepDef returns the step definition matching the text.
+// Definitions added for the keyword (or for any keyword) are preferred to definitions added for other keywords.
+// If more than one definition matches, the first registered one is returned with an *ambiguousStepError.
+func (s *Suite) findStepDef(text string, keyword stepKeyword) (stepDef, error) {
+	var found, otherKeywords []stepDef
 
 	for _, step := range s.steps {
 		if !step.expr.MatchString(text) {
 			continue
 		}
 
-		if l := len(step.expr.FindAll([]byte(text), -1)); l > found {
-			found = l
-			sd = step
+		if keyword == anyKeyword || step.keyword == anyKeyword || step.keyword == keyword {
+			found = append(found, step)
+		} else {
+			otherKeywords = append(otherKeywords, step)
+		}
+	}
+
+	if len(found) == 0 && len(otherKeywords) > 0 {
+		if s.options.strictKeywords {
+			return otherKeywords[0], &wrongKeywordError{text: text, keyword: keyword, defs: otherKeywords}
 		}
+
+		found = otherKeywords
+	}
+
+	if len(found) == 0 {
+		return stepDef{}, errStepNotFound
 	}
 
-	if reflect.DeepEqual(sd, stepDef{}) {
-		return sd, errors.New("cannot find step definition")
+	if len(found) > 1 {
+		return found[0], &ambiguousStepError{text: text, defs: found}
 	}
 
-	return sd, nil
+	return found[0], nil
 }
 
 func (s *Suite) skipScenario(scenarioTags []*msgs.GherkinDocument_Feature_Tag) bool {
-	for _, tag := range scenarioTags {
-		if contains(s.options.ignoreTags, tag.Name) {
-			return true
+	return !s.tagFilter.evaluate(tagNames(scenarioTags))
+}
+
+// skipFeature tells whether none of the feature's scenarios or examples match the tag filter and lines
+func (s *Suite) skipFeature(feature *msgs.GherkinDocument_Feature) bool {
+	var scenarios []*msgs.GherkinDocument_Feature_Scenario
+
+	for _, child := range feature.GetChildren() {
+		if rule := child.GetRule(); rule != nil {
+			for _, ruleChild := range rule.GetChildren() {
+				if scenario := ruleChild.GetScenario(); scenario != nil {
+					scenarios = append(scenarios, scenario)
+				}
+			}
 		}
-	}
 
-	if len(s.options.tags) == 0 {
-		return false
+		if scenario := child.GetScenario(); scenario != nil {
+			scenarios = append(scenarios, scenario)
+		}
 	}
 
-	for _, tag := range scenarioTags {
-		if contains(s.options.tags, tag.Name) {
+	for _, scenario := range scenarios {
+		tags := inheritTags(feature.GetTags(), scenario.GetTags())
+
+		if len(scenario.GetExamples()) == 0 && !s.skipScenario(tags) && s.matchesLines(scenario.GetLocation()) {
 			return false
 		}
+
+		for _, example := range scenario.GetExamples() {
+			if s.skipScenario(inheritTags(tags, example.GetTags())) {
+				continue
+			}
+
+			for _, row := range example.GetTableBody() {
+				if s.matchesLines(scenario.GetLocation(), example.GetLocation(), row.GetLocation()) {
+					return false
+				}
+			}
+		}
+	}
+
+	return len(scenarios) > 0
+}
+
+// matchesLines tells whether any of the locations is at one of the lines given in the features path.
+// Every location matches when no lines are given.
+func (s *Suite) matchesLines(locations ...*msgs.Location) bool {
+	if len(s.options.lines) == 0 {
+		return true
+	}
+
+	for _, location := range locations {
+		for _, line := range s.options.lines {
+			if int64(location.GetLine()) == line {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// newTagFilter combines the tag expression with tags configured by WithTags and WithIgnoredTags
+func newTagFilter(options SuiteOptions) (tagExpression, error) {
+	filter, err := parseTagExpression(options.tagExpression)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(options.tags) > 0 {
+		var tags tagExpression = tagLiteral(options.tags[0])
+		for _, tag := range options.tags[1:] {
+			tags = tagOr{left: tags, right: tagLiteral(tag)}
+		}
+
+		filter = tagAnd{left: filter, right: tags}
 	}
 
-	return true
+	for _, tag := range options.ignoreTags {
+		filter = tagAnd{left: filter, right: tagNot{expr: tagLiteral(tag)}}
+	}
+
+	return filter, nil
 }
 
 func (s *Suite) getBackgroundSteps(bkg *msgs.GherkinDocument_Feature_Background) []*msgs.GherkinDocument_Feature_Step {
@@ -706,17 +1582,3 @@ func contains(a []string, x string) bool {
 
 	return false
 }
-
-func getRegexpForVar(v interface{}) string {
-	s := v.(string)
-
-	if _, err := strconv.Atoi(s); err == nil {
-		return "(\\d+)"
-	}
-
-	if _, err := strconv.ParseFloat(s, 32); err == nil {
-		return "([+-]?([0-9]*[.])?[0-9]+)"
-	}
-
-	return "(.*)"
-}
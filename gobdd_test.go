@@ -1,11 +1,25 @@
 package gobdd
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"os/exec"
 	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/anuragh27crony/gobdd/formatter/cucumber"
 	msgs "github.com/cucumber/messages-go/v12"
 	"github.com/go-bdd/assert"
 )
@@ -59,54 +73,1027 @@ func TestParameterTypes(t *testing.T) {
 			t.Fatal("it should say that I like pizza")
 		}
 	})
+	suite.AddStep(`I use string {string}`, func(t StepTest, ctx Context, text string) {
+		if text != "I like pizza" {
+			t.Fatal("it should say that I like pizza")
+		}
+	})
+	suite.AddStep(`I eat/devour {int} pizza(s)`, func(t StepTest, ctx Context, n int) {
+		pizzas, _ := ctx.GetInt("pizzas", 0)
+		ctx.Set("pizzas", pizzas+n)
+	})
+	suite.AddStep(`I ate {int} pizza(s) in total`, func(t StepTest, ctx Context, n int) {
+		pizzas, _ := ctx.GetInt("pizzas", 0)
+		if err := assert.Equals(n, pizzas); err != nil {
+			t.Error(err)
+		}
+	})
+	suite.AddStep(`I pay {bigdecimal} and {bigdecimal}`, func(t StepTest, ctx Context, a, b *big.Float) {
+		ctx.Set("paid", new(big.Float).Add(a, b).String())
+	})
+	suite.AddStep(`I paid {bigdecimal}`, func(t StepTest, ctx Context, expected *big.Float) {
+		paid, _ := ctx.GetString("paid")
+		if err := assert.Equals(expected.String(), paid); err != nil {
+			t.Error(err)
+		}
+	})
+
+	suite.Run()
+}
+
+func TestInvalidStepExpression(t *testing.T) {
+	tester := &mockTester{}
+	suite := NewSuite(tester)
+	suite.AddStep(`I have {int} {color} cuke(s`, pass)
+
+	expected := []string{`the step is incorrect: the expression "I have {int} {color} cuke(s" is invalid at column 26: ` +
+		`the optional text is not closed`}
+	if err := assert.Equals(expected, tester.errors); err != nil {
+		t.Error(err)
+	}
+
+	if err := assert.Equals(true, suite.hasStepErrors); err != nil {
+		t.Error(err)
+	}
+}
+
+type color string
+
+type money struct {
+	cents    int
+	currency string
+}
+
+func parseMoney(value string) (money, error) {
+	var amount float64
+	var currency string
+
+	if _, err := fmt.Sscanf(value, "%f %s", &amount, &currency); err != nil {
+		return money{}, err
+	}
+
+	return money{cents: int(amount * 100), currency: currency}, nil
+}
+
+func TestCustomParameterTypes(t *testing.T) {
+	suite := NewSuite(t, WithFeaturesPath("features/custom-parameter-types.feature"))
+	suite.AddParameterTypeWithTransformer(`{color}`, []string{`red|green|blue`}, func(value string) (color, error) {
+		return color(value), nil
+	})
+	suite.AddParameterTypeWithTransformer(`{money}`, []string{`\d+(?:\.\d+)? [A-Z]{3}`}, parseMoney)
+	suite.AddStep(`the car is {color}`, func(t StepTest, ctx Context, c color) {
+		ctx.Set(color(""), c)
+	})
+	suite.AddStep(`I pay {money} for the car`, func(t StepTest, ctx Context, m money) {
+		ctx.Set(money{}, m)
+	})
+	suite.AddStep(`the {color} car should cost {int} cents`, func(t StepTest, ctx Context, c color, cents int) {
+		carColor, _ := ctx.Get(color(""))
+		price, _ := ctx.Get(money{})
+
+		if err := assert.Equals(c, carColor); err != nil {
+			t.Error(err)
+		}
+
+		if err := assert.Equals(money{cents: cents, currency: "EUR"}, price); err != nil {
+			t.Error(err)
+		}
+	})
+
+	suite.Run()
+}
+
+func TestCustomParameterTypeTransformerError(t *testing.T) {
+	suite := NewSuite(t)
+	suite.AddParameterTypeWithTransformer(`{money}`, []string{`\S+ [A-Z]{3}`}, func(value string) (money, error) {
+		return money{}, errors.New("the amount is not a number")
+	})
+	suite.AddStep(`I pay {money}`, func(t StepTest, ctx Context, m money) {
+		t.Error("the step should not be called")
+	})
+
+	def := suite.steps[0]
+	tester := &mockTester{}
+	stepErr := def.run(NewContext(), tester, def.arguments("I pay ten EUR"), nil, "features/pay.feature:3")
+
+	expected := `cannot transform "ten EUR" into {money}: the amount is not a number (features/pay.feature:3)`
+	if err := assert.Equals([]string{expected}, tester.errors); err != nil {
+		t.Error(err)
+	}
+
+	if err := assert.Equals(expected, fmt.Sprint(stepErr)); err != nil {
+		t.Errorf("the error should be returned to be written to the report: %s", err)
+	}
+}
+
+func TestInvalidParameterTypeTransformer(t *testing.T) {
+	testCases := map[string]interface{}{
+		"not a function":         "",
+		"without arguments":      func() (int, error) { return 0, nil },
+		"with invalid argument":  func(int) (int, error) { return 0, nil },
+		"without error":          func(string) int { return 0 },
+		"with invalid 2nd value": func(string) (int, bool) { return 0, false },
+	}
+
+	for name, transformer := range testCases {
+		t.Run(name, func(t *testing.T) {
+			tester := &mockTester{}
+			suite := NewSuite(tester)
+			suite.AddParameterTypeWithTransformer(`{value}`, []string{`.+`}, transformer)
+
+			if err := assert.Equals(1, tester.fatalCalled); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestAmbiguousSteps(t *testing.T) {
+	var result struct {
+		Feature   cucumber.Feature
+		Locations []string
+	}
+
+	ok := runIsolated(t, &result, func(t *testing.T) {
+		suite := NewSuite(t, WithFeaturesPath("features/ambiguous.feature"))
+		suite.AddStep(`I add {int} and {int}`, add)
+		suite.AddStep(`I add (\d+) and (\d+)`, add)
+		suite.AddStep(`the result should equal {int}`, check)
+
+		for _, def := range suite.steps {
+			result.Locations = append(result.Locations, def.origin())
+		}
+
+		result.Feature, _ = suite.executeFeature("features/ambiguous.feature")
+	})
+
+	feature, locations := result.Feature, result.Locations
+
+	if err := assert.Equals(false, ok); err != nil {
+		t.Errorf("the ambiguous step should fail the test: %s", err)
+	}
+
+	step := feature.Elements[0].Steps[0]
+	if err := assert.Equals("ambiguous", step.StepResult.RunStatus); err != nil {
+		t.Error(err)
+	}
+
+	expected := fmt.Sprintf("the step \"I add 1 and 2\" is ambiguous, it matches:\n\tI add {int} and {int} at %s\n\tI add (\\d+) and (\\d+) at %s",
+		locations[0], locations[1])
+	if err := assert.Equals(expected, step.StepResult.ErrorMsg); err != nil {
+		t.Error(err)
+	}
+
+	if !strings.Contains(locations[0], "gobdd_test.go:") || !strings.HasSuffix(locations[0], " (gobdd.add)") {
+		t.Errorf("the location %s should point to the test and the step function", locations[0])
+	}
+}
+
+func TestAmbiguousStepsWarning(t *testing.T) {
+	c := 0
+	suite := NewSuite(t, WithFeaturesPath("features/ambiguous.feature"), WithAmbiguousStepsWarning())
+	suite.AddStep(`I add {int} and {int}`, func(t StepTest, ctx Context, var1, var2 int) {
+		c++
+		add(t, ctx, var1, var2)
+	})
+	suite.AddStep(`I add (\d+) and (\d+)`, func(t StepTest, ctx Context, var1, var2 int) {
+		t.Error("the second step definition should not be used")
+	})
+	suite.AddStep(`the result should equal {int}`, check)
+
+	suite.Run()
+
+	if err := assert.Equals(1, c); err != nil {
+		t.Errorf("the first registered step definition should be used: %s", err)
+	}
+}
+
+func TestUndefinedSteps(t *testing.T) {
+	var result struct {
+		Feature  cucumber.Feature
+		Snippets []string
+		Output   string
+	}
+
+	ok := runIsolated(t, &result, func(t *testing.T) {
+		suite := NewSuite(t, WithFeaturesPath("features/undefined.feature"))
+		suite.AddStep(`I eat {int} pizza {string}`, func(t StepTest, ctx Context, n int, name string) {})
+
+		output := &bytes.Buffer{}
+		suite.out = output
+
+		result.Feature, _ = suite.executeFeature("features/undefined.feature")
+		result.Snippets = suite.undefinedSteps
+
+		suite.printUndefinedSteps()
+		result.Output = output.String()
+	})
+
+	feature, snippets := result.Feature, result.Snippets
+
+	if err := assert.Equals(true, ok); err != nil {
+		t.Errorf("the undefined step should not fail the test without the strict mode: %s", err)
+	}
+
+	if err := assert.Equals([][]string{{"undefined", "skipped", "undefined"}}, stepStatuses(feature)); err != nil {
+		t.Error(err)
+	}
+
+	expectedMsg := "cannot find step definition for step: Given I have 2 pizzas (features/undefined.feature:3)"
+	if err := assert.Equals(expectedMsg, feature.Elements[0].Steps[0].StepResult.ErrorMsg); err != nil {
+		t.Error(err)
+	}
+
+	expected := []string{"suite.AddStep(`I have {int} pizzas`, func(t gobdd.StepTest, ctx gobdd.Context, arg1 int) {\n\tt.Fatal(\"not implemented\")\n})"}
+	if err := assert.Equals(expected, snippets); err != nil {
+		t.Error(err)
+	}
+
+	expectedOutput := "Undefined steps:\n" +
+		"\tGiven I have 2 pizzas (features/undefined.feature:3)\n" +
+		"\tThen I have 2 pizzas (features/undefined.feature:5)\n\n" +
+		"You can implement undefined steps with these snippets:\n\n" + expected[0] + "\n\n"
+	if err := assert.Equals(expectedOutput, result.Output); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStrict(t *testing.T) {
+	for name, strict := range map[string]bool{"default": false, "strict": true} {
+		strict := strict
+
+		t.Run(name, func(t *testing.T) {
+			var feature cucumber.Feature
+
+			ok := runIsolated(t, &feature, func(t *testing.T) {
+				options := []func(*SuiteOptions){WithFeaturesPath("features/strict.feature")}
+				if strict {
+					options = append(options, WithStrict())
+				}
+
+				suite := NewSuite(t, options...)
+				suite.AddStep(`the step is pending`, func() error {
+					return ErrPending
+				})
+
+				feature, _ = suite.executeFeature("features/strict.feature")
+			})
+
+			if err := assert.Equals(!strict, ok); err != nil {
+				t.Errorf("pending and undefined steps should fail the test only in the strict mode: %s", err)
+			}
+
+			if err := assert.Equals([][]string{{"pending"}, {"undefined"}}, stepStatuses(feature)); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func addKeywordSteps(suite *Suite) {
+	suite.AddGiven(`the counter is {int}`, func(t StepTest, ctx Context, counter int) {
+		ctx.Set("counter", counter)
+	})
+	suite.AddWhen(`I increment the counter`, func(t StepTest, ctx Context) {
+		counter, _ := ctx.GetInt("counter")
+		ctx.Set("counter", counter+1)
+	})
+	suite.AddThen(`the counter is {int}`, func(t StepTest, ctx Context, expected int) {
+		counter, _ := ctx.GetInt("counter")
+		if err := assert.Equals(expected, counter); err != nil {
+			t.Error(err)
+		}
+	})
+	suite.AddThen(`the counter is not {int}`, func(t StepTest, ctx Context, unexpected int) {
+		counter, _ := ctx.GetInt("counter")
+		if counter == unexpected {
+			t.Errorf("the counter should not equal %d", unexpected)
+		}
+	})
+}
+
+func TestKeywords(t *testing.T) {
+	suite := NewSuite(t, WithFeaturesPath("features/keywords.feature"))
+	addKeywordSteps(suite)
+
+	suite.Run()
+}
+
+func TestStrictKeywords(t *testing.T) {
+	var feature cucumber.Feature
+
+	ok := runIsolated(t, &feature, func(t *testing.T) {
+		suite := NewSuite(t, WithFeaturesPath("features/keywords.feature"), WithStrictKeywords())
+		addKeywordSteps(suite)
+
+		feature, _ = suite.executeFeature("features/keywords.feature")
+	})
+
+	if err := assert.Equals(false, ok); err != nil {
+		t.Errorf("the step used with another keyword should fail the test: %s", err)
+	}
+
+	for _, step := range feature.Elements[0].Steps {
+		if err := assert.Equals("passed", step.StepResult.RunStatus); err != nil {
+			t.Errorf("%s: %s", step.Name, err)
+		}
+	}
+
+	step := feature.Elements[1].Steps[1]
+	if err := assert.Equals("failed", step.StepResult.RunStatus); err != nil {
+		t.Error(err)
+	}
+
+	if !strings.HasPrefix(step.StepResult.ErrorMsg, "the step \"I increment the counter\" is used as Then but it matches only:\n\tI increment the counter for When at ") {
+		t.Errorf("unexpected error message: %s", step.StepResult.ErrorMsg)
+	}
+}
+
+func TestStepErrors(t *testing.T) {
+	var feature cucumber.Feature
+
+	ok := runIsolated(t, &feature, func(t *testing.T) {
+		suite := NewSuite(t, WithFeaturesPath("features/step-errors.feature"))
+		suite.AddStep(`the step passes`, func(t StepTest, ctx Context) error {
+			return nil
+		})
+		suite.AddStep(`the step returns an error`, func(t StepTest, ctx Context) error {
+			return errors.New("the order cannot be placed")
+		})
+		suite.AddStep(`the step is pending`, func(t StepTest, ctx Context) error {
+			return fmt.Errorf("waiting for the API: %w", ErrPending)
+		})
+		suite.AddStep(`the step is skipped`, func(t StepTest, ctx Context) error {
+			return fmt.Errorf("the API is down: %w", ErrSkip)
+		})
+		suite.AddStep(`the step has {word} apples`, func(t StepTest, ctx Context, apples int) error {
+			return nil
+		})
+
+		feature, _ = suite.executeFeature("features/step-errors.feature")
+	})
+
+	if err := assert.Equals(false, ok); err != nil {
+		t.Errorf("the error should fail the test: %s", err)
+	}
+
+	var statuses, messages []string
+	for _, scenario := range feature.Elements {
+		statuses = append(statuses, scenario.Steps[0].StepResult.RunStatus)
+		messages = append(messages, scenario.Steps[0].StepResult.ErrorMsg)
+	}
+
+	if err := assert.Equals([]string{"passed", "failed", "pending", "skipped", "failed"}, statuses); err != nil {
+		t.Error(err)
+	}
+
+	expectedMessages := []string{
+		"",
+		"the order cannot be placed",
+		"waiting for the API: the step is pending",
+		"",
+		`cannot convert argument 1 "2.5" to int: strconv.ParseInt: parsing "2.5": invalid syntax (features/step-errors.feature:15)`,
+	}
+	if err := assert.Equals(expectedMessages, messages); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestInjectedArguments(t *testing.T) {
+	suite := NewSuite(t, WithFeaturesPath("features/injected-arguments.feature"))
+	suite.AddStep(`I have {int} apples`, func(apples int, ctx Context) {
+		ctx.Set("apples", apples)
+	})
+	suite.AddStep(`I eat {int} of them`, func(ctx Context, eaten int, t StepTest) {
+		apples, _ := ctx.GetInt("apples")
+		ctx.Set("apples", apples-eaten)
+	})
+	suite.AddStep(`the scenario info is injected`, func(info ScenarioInfo, t *testing.T) {
+		expected := ScenarioInfo{
+			FeatureFile: "features/injected-arguments.feature",
+			FeatureName: "injected arguments",
+			Name:        "parameters in any order",
+			Line:        3,
+			Tags:        []string{"@injected"},
+		}
+
+		if err := assert.Equals(expected, info); err != nil {
+			t.Error(err)
+		}
+	})
+	suite.AddStep(`the contexts are injected`, func(stdCtx context.Context, t StepTest, ctx Context) error {
+		if stdCtx.Err() != nil {
+			return fmt.Errorf("the context should not be done: %v", stdCtx.Err())
+		}
+
+		apples, err := ctx.GetInt("apples")
+		if err != nil {
+			return err
+		}
+
+		return assert.Equals(1, apples)
+	})
+	suite.AddStep(`the table is injected:`, func(table DataTable, t StepTest) {
+		if err := assert.Equals(2, len(table.Rows())); err != nil {
+			t.Error(err)
+		}
+	})
+	suite.AddStep(`the scenario is named {string}`, func(name string, info ScenarioInfo) error {
+		if err := assert.Equals(name, info.Name); err != nil {
+			return err
+		}
+
+		return assert.Equals(17, info.Line)
+	})
+
+	suite.Run()
+}
+
+func TestDryRun(t *testing.T) {
+	var result struct {
+		Feature cucumber.Feature
+		Called  int
+	}
+
+	hook := func(ctx Context) { result.Called++ }
+
+	ok := runIsolated(t, &result, func(t *testing.T) {
+		suite := NewSuite(t, WithFeaturesPath("features/dry-run.feature"), WithDryRun(),
+			WithBeforeScenario(hook), WithAfterScenario(hook), WithBeforeStep(hook), WithAfterStep(hook))
+		suite.AddStep(`I have {int} apple(s)`, func(t StepTest, ctx Context, n int) { result.Called++ })
+		suite.AddStep(`I eat {word} apples`, func(t StepTest, ctx Context, n int) { result.Called++ })
+		suite.AddStep(`I have 1 apple`, func(t StepTest, ctx Context) { result.Called++ })
+
+		result.Feature, _ = suite.executeFeature("features/dry-run.feature")
+	})
+
+	feature := result.Feature
+
+	if err := assert.Equals(false, ok); err != nil {
+		t.Errorf("the dry run should fail because of incorrect steps: %s", err)
+	}
+
+	if err := assert.Equals(0, result.Called); err != nil {
+		t.Errorf("step functions and hooks should not be called: %s", err)
+	}
+
+	statuses := stepStatuses(feature)
+
+	// the first step of every scenario is the background step
+	expected := [][]string{
+		{"skipped", "skipped", "failed", "undefined", "ambiguous"},
+		{"skipped", "skipped"},
+		{"skipped", "failed"},
+	}
+	if err := assert.Equals(expected, statuses); err != nil {
+		t.Error(err)
+	}
+
+	step := feature.Elements[0].Steps[2]
+	expectedMsg := `cannot convert argument 1 "many" to int: strconv.ParseInt: parsing "many": invalid syntax (features/dry-run.feature:7)`
+	if err := assert.Equals(expectedMsg, step.StepResult.ErrorMsg); err != nil {
+		t.Error(err)
+	}
+
+	if !strings.Contains(step.Match.Location, "gobdd_test.go:") {
+		t.Errorf("the mis-typed step should point to the step definition: %s", step.Match.Location)
+	}
+}
+
+func TestSkipStepsAfterFailure(t *testing.T) {
+	var result struct {
+		Feature       cucumber.Feature
+		Snippets      []string
+		Passed        int
+		AfterScenario int
+	}
+
+	ok := runIsolated(t, &result, func(t *testing.T) {
+		suite := NewSuite(t, WithFeaturesPath("features/failing-steps.feature"),
+			WithAfterScenario(func(ctx Context) { result.AfterScenario++ }))
+		suite.AddStep(`the step passes`, func() { result.Passed++ })
+		suite.AddStep(`the step fails`, func() error { return errors.New("the step failed") })
+
+		result.Feature, _ = suite.executeFeature("features/failing-steps.feature")
+		result.Snippets = suite.undefinedSteps
+	})
+
+	feature, passed, afterScenario := result.Feature, result.Passed, result.AfterScenario
+
+	if err := assert.Equals(false, ok); err != nil {
+		t.Errorf("the failing step should fail the test: %s", err)
+	}
+
+	statuses := stepStatuses(feature)
+
+	expected := [][]string{{"passed", "failed", "skipped", "undefined"}, {"failed", "skipped"}}
+	if err := assert.Equals(expected, statuses); err != nil {
+		t.Error(err)
+	}
+
+	if err := assert.Equals(1, len(result.Snippets)); err != nil {
+		t.Errorf("undefined steps after the failing one should be reported: %s", err)
+	}
+
+	if err := assert.Equals(1, passed); err != nil {
+		t.Errorf("steps after the failing one should not be executed: %s", err)
+	}
+
+	if err := assert.Equals(2, afterScenario); err != nil {
+		t.Errorf("after-scenario hooks should run after failing steps: %s", err)
+	}
+}
+
+func TestFailFast(t *testing.T) {
+	var result struct {
+		Report        []byte
+		Passed        int
+		AfterScenario int
+	}
+
+	ok := runIsolated(t, &result, func(t *testing.T) {
+		report, err := ioutil.TempFile("", "gobdd-report-*.json")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		report.Close()
+		defer os.Remove(report.Name())
+
+		suite := NewSuite(t, WithFeaturesPath("features/fail-fast/*.feature"), WithFailFast(),
+			WithAfterScenario(func(ctx Context) { result.AfterScenario++ }))
+		suite.AddStep(`the step passes`, func() { result.Passed++ })
+		suite.AddStep(`the step fails`, func() error { return errors.New("the step failed") })
+		suite.WithJsonReport(report.Name())
+		suite.Run()
+
+		result.Report, err = ioutil.ReadFile(report.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	passed, afterScenario := result.Passed, result.AfterScenario
+
+	if err := assert.Equals(false, ok); err != nil {
+		t.Errorf("the failing scenario should fail the test: %s", err)
+	}
+
+	// the passing scenario and the background of both executed scenarios
+	if err := assert.Equals(3, passed); err != nil {
+		t.Errorf("scenarios after the failed one should not be executed: %s", err)
+	}
+
+	if err := assert.Equals(2, afterScenario); err != nil {
+		t.Errorf("after-scenario hooks of started scenarios should run: %s", err)
+	}
+
+	var features []cucumber.Feature
+	if err := json.Unmarshal(result.Report, &features); err != nil {
+		t.Fatal(err)
+	}
+
+	var statuses [][]string
+	for _, feature := range features {
+		statuses = append(statuses, stepStatuses(feature)...)
+	}
+
+	// skipped scenarios begin with the background steps like executed ones
+	expected := [][]string{
+		{"passed", "passed"}, {"passed", "failed"}, {"skipped", "skipped"}, {"skipped", "skipped"},
+		{"skipped"}, {"skipped"}, {"skipped"}, {"skipped", "skipped"},
+	}
+	if err := assert.Equals(expected, statuses); err != nil {
+		t.Error(err)
+	}
+
+	// rows of outlines in skipped features are expanded like in executed features
+	var rows []string
+	for _, scenario := range features[1].Elements[1:3] {
+		rows = append(rows, fmt.Sprintf("%s: %s %v", scenario.Name, scenario.Steps[0].Name, scenario.Tags))
+	}
+
+	expectedRows := []string{
+		"outline with passes steps: the step passes [{@outline 5}]",
+		"outline with fails steps: the step fails [{@outline 5}]",
+	}
+	if err := assert.Equals(expectedRows, rows); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestConcurrency(t *testing.T) {
+	var running, maxRunning int32
+	var mu sync.Mutex
+	var started []string
+
+	start := func(info ScenarioInfo) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		started = append(started, info.Name)
+	}
+
+	suite := NewSuite(t, WithFeaturesPath("features/concurrency.feature"), WithConcurrency(2))
+	suite.AddStep(`the scenario runs`, func(info ScenarioInfo) {
+		start(info)
+
+		current := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+				break
+			}
+		}
+
+		time.Sleep(20 * time.Millisecond)
+	})
+	suite.AddStep(`the scenario runs alone`, func(t StepTest, info ScenarioInfo) {
+		start(info)
+
+		current := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+
+		time.Sleep(20 * time.Millisecond)
+
+		if err := assert.Equals(int32(1), current); err != nil {
+			t.Errorf("the @serial scenario should run alone: %s", err)
+		}
+	})
+
+	feature, err := suite.executeFeature("features/concurrency.feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// parallel tests are limited by the -parallel flag as well
+	parallel, _ := strconv.Atoi(flag.Lookup("test.parallel").Value.String())
+	if max := atomic.LoadInt32(&maxRunning); max > 2 || (max < 2 && parallel > 1) {
+		t.Errorf("2 scenarios should run at the same time but %d were running", max)
+	}
+
+	var names []string
+	for _, scenario := range feature.Elements {
+		names = append(names, scenario.Name)
+
+		if err := assert.Equals("passed", scenario.Steps[0].StepResult.RunStatus); err != nil {
+			t.Errorf("%s: %s", scenario.Name, err)
+		}
+	}
+
+	expected := []string{"first", "second", "outline 1", "outline 2", "serial", "third", "rule", "serial rule"}
+	if err := assert.Equals(expected, names); err != nil {
+		t.Errorf("the report should keep the order of scenarios: %s", err)
+	}
+
+	// scenarios of outlines and rules wait for the feature like other parallel scenarios,
+	// only the @serial scenario of the feature runs before them
+	if err := assert.Equals("serial", started[0]); err != nil {
+		t.Errorf("outlines and rules should not run before earlier scenarios: %s", err)
+	}
+}
+
+func TestScenarioOutlineExecutesAllTests(t *testing.T) {
+	c := 0
+	suite := NewSuite(t, WithFeaturesPath("features/outline.feature"))
+	suite.AddStep(`I add (\d+) and (\d+)`, add)
+	suite.AddStep(`the result should equal (\d+)`, func(t StepTest, ctx Context, sum int) {
+		c++
+		check(t, ctx, sum)
+	})
+
+	suite.Run()
+
+	if err := assert.Equals(2, c); err != nil {
+		t.Errorf("expected to run %d times but %d got", 2, c)
+	}
+}
+
+func TestScenarioOutlineArguments(t *testing.T) {
+	c := 0
+	suite := NewSuite(t, WithFeaturesPath("features/outline-arguments.feature"))
+	suite.AddStep(`the users:`, func(t StepTest, ctx Context, table DataTable) {
+		ctx.Set("user", table.Maps()[0]["name"])
+	})
+	suite.AddStep(`the payload:`, func(t StepTest, ctx Context, payload DocString) {
+		var user struct {
+			Name string `json:"name"`
+		}
+
+		if err := payload.DecodeJSON(&user); err != nil {
+			t.Fatal(err)
+		}
+
+		ctx.Set("payload", user.Name)
+	})
+	suite.AddStep(`the user should be called (\w+) and the payload should be json`, func(t StepTest, ctx Context, name string) {
+		c++
+
+		user, err := ctx.GetString("user")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := assert.Equals(name, user); err != nil {
+			t.Error(err)
+		}
+
+		payload, err := ctx.GetString("payload")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := assert.Equals(name, payload); err != nil {
+			t.Error(err)
+		}
+	})
+
+	suite.Run()
+
+	if err := assert.Equals(2, c); err != nil {
+		t.Errorf("expected to run %d times but %d got", 2, c)
+	}
+}
+
+func TestScenarioOutlineRowsAreSeparateScenarios(t *testing.T) {
+	hooks := 0
+	suite := NewSuite(t, WithFeaturesPath("features/outline-rows.feature"), WithBeforeScenario(func(ctx Context) {
+		hooks++
+	}))
+	suite.AddStep(`I add (\d+) to the counter`, func(t StepTest, ctx Context, value int) {
+		counter, err := ctx.GetInt("counter", 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx.Set("counter", counter+value)
+	})
+	suite.AddStep(`the counter should equal (\d+)`, func(t StepTest, ctx Context, expected int) {
+		counter, err := ctx.GetInt("counter")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := assert.Equals(expected, counter); err != nil {
+			t.Error(err)
+		}
+	})
+
+	feature, err := suite.executeFeature("features/outline-rows.feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := assert.Equals(2, hooks); err != nil {
+		t.Errorf("expected to call the hook %d times but %d got", 2, hooks)
+	}
+
+	var names []string
+	for _, scenario := range feature.Elements {
+		names = append(names, scenario.Name)
+	}
+
+	if err := assert.Equals([]string{"adding 1 to the counter", "adding 2 to the counter"}, names); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStepFromExample(t *testing.T) {
+	s := NewSuite(t)
+	st := s.stepFromExample("I add <d1> and <d2>", &msgs.GherkinDocument_Feature_TableRow{
+		Cells: []*msgs.GherkinDocument_Feature_TableRow_TableCell{
+			{Value: "1"},
+			{Value: "2"},
+		},
+	}, []string{"<d1>", "<d2>"})
+
+	if err := assert.NotNil(st); err != nil {
+		t.Error(err)
+	}
+
+	if err := assert.Equals("I add 1 and 2", st); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBackground(t *testing.T) {
+	suite := NewSuite(t, WithFeaturesPath("features/background.feature"))
+	suite.AddStep(`I add (\d+) and (\d+)`, add)
+	suite.AddStep(`the result should equal (\d+)`, check)
+
+	suite.Run()
+}
+
+func TestDataTable(t *testing.T) {
+	type user struct {
+		Name string `table:"name"`
+		Age  int    `table:"age"`
+	}
+
+	suite := NewSuite(t, WithFeaturesPath("features/datatable.feature"))
+	suite.AddStep(`the users:`, func(t StepTest, ctx Context, table DataTable) {
+		var users []user
+		if err := table.Decode(&users); err != nil {
+			t.Fatal(err)
+		}
+
+		ctx.Set("users", users)
+	})
+	suite.AddStep(`there should be (\d+) users`, func(t StepTest, ctx Context, count int) {
+		users, err := ctx.Get("users")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := assert.Equals(count, len(users.([]user))); err != nil {
+			t.Error(err)
+		}
+	})
+
+	suite.Run()
+}
+
+func TestDocString(t *testing.T) {
+	suite := NewSuite(t, WithFeaturesPath("features/docstring.feature"))
+	suite.AddStep(`the text:`, func(t StepTest, ctx Context, text DocString) {
+		ctx.Set("text", text.Content)
+	})
+	suite.AddStep(`the text should have (\d+) lines`, func(t StepTest, ctx Context, lines int) {
+		text, err := ctx.GetString("text")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := assert.Equals(lines, len(strings.Split(text, "\n"))); err != nil {
+			t.Error(err)
+		}
+	})
+	suite.AddStep(`the payload:`, func(t StepTest, ctx Context, payload DocString) {
+		var user struct {
+			Name string `json:"name"`
+		}
+
+		if err := payload.DecodeJSON(&user); err != nil {
+			t.Fatal(err)
+		}
+
+		ctx.Set("name", user.Name)
+	})
+	suite.AddStep(`the name should equal "(\w+)"`, func(t StepTest, ctx Context, name string) {
+		received, err := ctx.GetString("name")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := assert.Equals(name, received); err != nil {
+			t.Error(err)
+		}
+	})
+
+	suite.Run()
+}
+
+func TestStepArgumentMismatch(t *testing.T) {
+	withoutArgument := func(t StepTest, ctx Context) {
+		t.Error("the step should not be called")
+	}
+	withDataTable := func(t StepTest, ctx Context, table DataTable) {
+		t.Error("the step should not be called")
+	}
+
+	testCases := map[string]struct {
+		f        interface{}
+		argument interface{}
+		expected string
+	}{
+		"data table not accepted": {
+			f:        withoutArgument,
+			argument: DataTable{},
+			expected: "the step has gobdd.DataTable but the step function func(gobdd.StepTest, gobdd.Context) doesn't accept it (features/users.feature:3)",
+		},
+		"doc string not accepted": {
+			f:        withoutArgument,
+			argument: DocString{},
+			expected: "the step has gobdd.DocString but the step function func(gobdd.StepTest, gobdd.Context) doesn't accept it (features/users.feature:3)",
+		},
+		"data table expected but missing": {
+			f:        withDataTable,
+			expected: "the step function func(gobdd.StepTest, gobdd.Context, gobdd.DataTable) expects gobdd.DataTable but the step doesn't have one (features/users.feature:3)",
+		},
+		"data table expected but doc string given": {
+			f:        withDataTable,
+			argument: DocString{},
+			expected: "the step function func(gobdd.StepTest, gobdd.Context, gobdd.DataTable) expects gobdd.DataTable but the step has gobdd.DocString (features/users.feature:3)",
+		},
+	}
 
-	suite.Run()
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			def := stepDef{f: testCase.f}
+
+			tester := &mockTester{}
+			stepErr := def.run(NewContext(), tester, nil, testCase.argument, "features/users.feature:3")
+
+			if err := assert.Equals([]string{testCase.expected}, tester.errors); err != nil {
+				t.Error(err)
+			}
+
+			if err := assert.Equals(testCase.expected, fmt.Sprint(stepErr)); err != nil {
+				t.Error(err)
+			}
+		})
+	}
 }
 
-func TestScenarioOutlineExecutesAllTests(t *testing.T) {
+func TestRule(t *testing.T) {
 	c := 0
-	suite := NewSuite(t, WithFeaturesPath("features/outline.feature"))
-	suite.AddStep(`I add (\d+) and (\d+)`, add)
-	suite.AddStep(`the result should equal (\d+)`, func(t StepTest, ctx Context, sum int) {
+	suite := NewSuite(t, WithFeaturesPath("features/rule.feature"))
+	suite.AddStep(`the counter is (\d+)`, func(t StepTest, ctx Context, counter int) {
+		ctx.Set("counter", counter)
+	})
+	suite.AddStep(`I increment the counter`, func(t StepTest, ctx Context) {
+		counter, err := ctx.GetInt("counter")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx.Set("counter", counter+1)
+	})
+	suite.AddStep(`the counter should equal (\d+)`, func(t StepTest, ctx Context, expected int) {
 		c++
-		check(t, ctx, sum)
+
+		counter, err := ctx.GetInt("counter")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := assert.Equals(expected, counter); err != nil {
+			t.Error(err)
+		}
 	})
 
 	suite.Run()
 
-	if err := assert.Equals(2, c); err != nil {
-		t.Errorf("expected to run %d times but %d got", 2, c)
+	if err := assert.Equals(3, c); err != nil {
+		t.Errorf("expected to run %d scenarios but %d got", 3, c)
 	}
 }
 
-func TestStepFromExample(t *testing.T) {
-	s := NewSuite(t)
-	st, expr := s.stepFromExample("I add <d1> and <d2>", &msgs.GherkinDocument_Feature_TableRow{
-		Cells: []*msgs.GherkinDocument_Feature_TableRow_TableCell{
-			{Value: "1"},
-			{Value: "2"},
-		},
-	}, []string{"<d1>", "<d2>"})
+func TestRuleReport(t *testing.T) {
+	suite := NewSuite(t, WithFeaturesPath("features/rule.feature"))
+	suite.AddStep(`the counter is (\d+)`, func(StepTest, Context, int) {})
+	suite.AddStep(`I increment the counter`, pass)
+	suite.AddStep(`the counter should equal (\d+)`, func(StepTest, Context, int) {})
 
-	if err := assert.NotNil(st); err != nil {
-		t.Error(err)
+	feature, err := suite.executeFeature("features/rule.feature")
+	if err != nil {
+		t.Fatal(err)
 	}
 
-	if err := assert.Equals("I add 1 and 2", st); err != nil {
-		t.Error(err)
+	var names []string
+	for _, scenario := range feature.Elements {
+		names = append(names, scenario.Name)
 	}
 
-	if err := assert.Equals(`I add (\d+) and (\d+)`, expr); err != nil {
+	expected := []string{
+		"scenario outside of rules",
+		"the rule's background runs after the feature's one",
+		"only the feature's background runs",
+	}
+	if err := assert.Equals(expected, names); err != nil {
 		t.Error(err)
 	}
 }
 
-func TestBackground(t *testing.T) {
-	suite := NewSuite(t, WithFeaturesPath("features/background.feature"))
+func TestStepDefinitionLocationInReport(t *testing.T) {
+	suite := NewSuite(t, WithFeaturesPath("features/example.feature"))
 	suite.AddStep(`I add (\d+) and (\d+)`, add)
 	suite.AddStep(`the result should equal (\d+)`, check)
 
-	suite.Run()
+	feature, err := suite.executeFeature("features/example.feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	location := feature.Elements[0].Steps[0].Match.Location
+	if err := assert.Equals(suite.steps[0].location, location); err != nil {
+		t.Error(err)
+	}
+
+	if !strings.Contains(location, "gobdd_test.go:") {
+		t.Errorf("the location %s should point to the test", location)
+	}
+
+	b, err := json.Marshal(feature.Elements[0].Steps[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(b), fmt.Sprintf(`"match":{"location":%q}`, location)) {
+		t.Errorf("the report should contain the location of the step definition: %s", b)
+	}
 }
 
 func TestTags(t *testing.T) {
@@ -117,6 +1104,112 @@ func TestTags(t *testing.T) {
 	suite.Run()
 }
 
+func TestTagExpression(t *testing.T) {
+	c := 0
+	suite := NewSuite(t, WithFeaturesPath("features/tag-expression.feature"),
+		WithTagExpression("@api and not @slow and (not @wip or @smoke)"))
+	suite.AddStep(`fail the test`, fail)
+	suite.AddStep(`the test should pass`, func(t StepTest, ctx Context) {
+		c++
+	})
+
+	suite.Run()
+
+	if err := assert.Equals(2, c); err != nil {
+		t.Errorf("expected to run %d scenarios but %d got", 2, c)
+	}
+}
+
+func TestTagExpressionSkipsFeature(t *testing.T) {
+	suite := NewSuite(t, WithFeaturesPath("features/tag-expression.feature"), WithTagExpression("not @api"))
+	suite.AddStep(`fail the test`, fail)
+	suite.AddStep(`the test should pass`, fail)
+
+	suite.Run()
+}
+
+func TestInvalidTagExpression(t *testing.T) {
+	tester := &mockTester{}
+	NewSuite(tester, WithTagExpression("@api and"))
+
+	if err := assert.Equals(1, tester.fatalCalled); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFeaturesPathWithLines(t *testing.T) {
+	testCases := map[string][]string{
+		"features/locations.feature:2":       {"first"},
+		"features/locations.feature:2:13":    {"first", "third"},
+		"features/locations.feature:14":      {"fourth", "fifth"},
+		"features/locations.feature:8":       {"second", "third", "fourth", "fifth"},
+		"features/locations.feature:12:3:17": {"second", "fifth"},
+	}
+
+	for path, expected := range testCases {
+		t.Run(path, func(t *testing.T) {
+			var names []string
+			suite := NewSuite(t, WithFeaturesPath(path))
+			suite.AddStep(`fail the test`, fail)
+			suite.AddStep(`the scenario "(\w+)" should run`, func(t StepTest, ctx Context, name string) {
+				names = append(names, name)
+			})
+
+			suite.Run()
+
+			if err := assert.Equals(expected, names); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestFeaturesPathFromEnv(t *testing.T) {
+	if err := os.Setenv(FeaturesPathEnv, "features/locations.feature:12"); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Unsetenv(FeaturesPathEnv)
+
+	var names []string
+	suite := NewSuite(t, WithFeaturesPath("features/example.feature"))
+	suite.AddStep(`fail the test`, fail)
+	suite.AddStep(`the scenario "(\w+)" should run`, func(t StepTest, ctx Context, name string) {
+		names = append(names, name)
+	})
+
+	suite.Run()
+
+	if err := assert.Equals([]string{"second"}, names); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSplitFeaturesPath(t *testing.T) {
+	testCases := map[string]struct {
+		path  string
+		lines []int64
+	}{
+		"features/*.feature":       {path: "features/*.feature"},
+		"features/x.feature:12":    {path: "features/x.feature", lines: []int64{12}},
+		"features/x.feature:12:20": {path: "features/x.feature", lines: []int64{12, 20}},
+		`C:\features\x.feature:3`:  {path: `C:\features\x.feature`, lines: []int64{3}},
+		"features/x.feature:abc":   {path: "features/x.feature:abc"},
+		"features/x.feature:0":     {path: "features/x.feature:0"},
+	}
+
+	for locator, expected := range testCases {
+		path, lines := splitFeaturesPath(locator)
+
+		if err := assert.Equals(expected.path, path); err != nil {
+			t.Errorf("%s: %s", locator, err)
+		}
+
+		if err := assert.Equals(expected.lines, lines); err != nil {
+			t.Errorf("%s: %s", locator, err)
+		}
+	}
+}
+
 func TestWithAfterScenario(t *testing.T) {
 	c := false
 	suite := NewSuite(t, WithFeaturesPath("features/empty.feature"), WithAfterScenario(func(ctx Context) {
@@ -171,6 +1264,103 @@ func TestWithBeforeStep(t *testing.T) {
 	}
 }
 
+func TestExamplesTags(t *testing.T) {
+	testCases := map[string]func(*SuiteOptions){
+		"with tags":         WithTags([]string{"@smoke"}),
+		"with ignored tags": WithIgnoredTags([]string{"@slow"}),
+	}
+
+	for name, option := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var values []int
+			suite := NewSuite(t, WithFeaturesPath("features/example-tags.feature"), option)
+			suite.AddStep(`the value (\d+) should be used`, func(t StepTest, ctx Context, value int) {
+				values = append(values, value)
+			})
+
+			feature, err := suite.executeFeature("features/example-tags.feature")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := assert.Equals([]int{1}, values); err != nil {
+				t.Error(err)
+			}
+
+			var tags [][]string
+			var statuses []string
+			for _, scenario := range feature.Elements {
+				var names []string
+				for _, tag := range scenario.Tags {
+					names = append(names, tag.Name)
+				}
+
+				tags = append(tags, names)
+				statuses = append(statuses, scenario.Steps[0].StepResult.RunStatus)
+			}
+
+			expectedTags := [][]string{{"@outline", "@smoke"}, {"@outline", "@slow"}, {"@outline", "@slow"}}
+			if err := assert.Equals(expectedTags, tags); err != nil {
+				t.Error(err)
+			}
+
+			if err := assert.Equals([]string{"passed", "skipped", "skipped"}, statuses); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestFeatureTagsInheritance(t *testing.T) {
+	c := 0
+	hooks := 0
+	suite := NewSuite(t, WithFeaturesPath("features/feature-tags.feature"), WithTags([]string{"@smoke"}),
+		WithBeforeScenario(func(ctx Context) {
+			tags, err := ctx.Get(TagsKey{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if contains(tags.([]string), "@smoke") {
+				hooks++
+			}
+		}))
+	suite.AddStep(`the scenario's tags should be "([\w@,]+)"`, func(t StepTest, ctx Context, expected string) {
+		c++
+
+		tags, err := ctx.Get(TagsKey{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := assert.Equals(expected, strings.Join(tags.([]string), ",")); err != nil {
+			t.Error(err)
+		}
+	})
+
+	feature, err := suite.executeFeature("features/feature-tags.feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := assert.Equals(2, c); err != nil {
+		t.Errorf("expected to run %d scenarios but %d got", 2, c)
+	}
+
+	if err := assert.Equals(2, hooks); err != nil {
+		t.Errorf("expected hooks to see the inherited tag %d times but %d got", 2, hooks)
+	}
+
+	var tags []string
+	for _, tag := range feature.Elements[0].Tags {
+		tags = append(tags, tag.Name)
+	}
+
+	if err := assert.Equals([]string{"@smoke", "@api"}, tags); err != nil {
+		t.Error(err)
+	}
+}
+
 func TestIgnoredTags(t *testing.T) {
 	suite := NewSuite(t, WithFeaturesPath("features/ignored_tags.feature"), WithIgnoredTags([]string{"@ignore"}))
 	suite.AddStep(`fail the test`, fail)
@@ -186,11 +1376,11 @@ func TestInvalidFunctionSignature(t *testing.T) {
 	testCases := map[string]struct {
 		f interface{}
 	}{
-		"nil":                              {},
-		"func without return value":        {f: func(ctx Context) {}},
-		"func with invalid return value":   {f: func(ctx Context) int { return 0 }},
-		"func without arguments":           {f: func() error { return errors.New("") }},
-		"func with invalid first argument": {f: func(i int) error { return errors.New("") }},
+		"nil":                               {},
+		"not a function":                    {f: "step"},
+		"func with invalid return value":    {f: func(ctx Context) int { return 0 }},
+		"func with two DataTables":          {f: func(DataTable, DataTable) {}},
+		"func with DataTable and DocString": {f: func(t StepTest, table DataTable, doc DocString) error { return nil }},
 	}
 
 	for name, testCase := range testCases {
@@ -207,6 +1397,77 @@ func TestInvalidFunctionSignature(t *testing.T) {
 	}
 }
 
+func TestInvalidStepDefinitions(t *testing.T) {
+	testCases := map[string]struct {
+		expr     string
+		f        interface{}
+		expected string
+	}{
+		"too few parameters": {
+			expr:     `I add {int} and {int}`,
+			f:        func(t StepTest, ctx Context, a int) {},
+			expected: "the expression captures 2 values but the function accepts 1",
+		},
+		"too many parameters": {
+			expr:     `I add {int}`,
+			f:        func(t StepTest, ctx Context, a, b int) {},
+			expected: "the expression captures 1 values but the function accepts 2",
+		},
+		"regexp with too few parameters": {
+			expr:     `^I add (\d+) and (\d+)$`,
+			f:        func(a int) {},
+			expected: "the expression captures 2 values but the function accepts 1",
+		},
+		"unsupported type": {
+			expr:     `I eat {word}`,
+			f:        func(ctx Context, food []string) {},
+			expected: "the argument 1 has the unsupported type []string",
+		},
+		"transformer returning another type": {
+			expr:     `the car is {color}`,
+			f:        func(c int) {},
+			expected: "the parameter {color} is gobdd.color but the function expects int as the argument 1",
+		},
+		"invalid return value": {
+			expr:     `I add {int}`,
+			f:        func(a int) int { return a },
+			expected: "the function can return only error or Context but it returns int",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			tester := &mockTester{}
+			suite := NewSuite(tester)
+			suite.AddParameterTypeWithTransformer(`{color}`, []string{`red|green|blue`}, func(value string) (color, error) {
+				return color(value), nil
+			})
+			suite.AddStep(testCase.expr, testCase.f)
+			suite.Run()
+
+			expected := []string{fmt.Sprintf("the step function for step `%s` is incorrect: %s", testCase.expr, testCase.expected)}
+			if err := assert.Equals(expected, tester.errors); err != nil {
+				t.Error(err)
+			}
+
+			if err := assert.Equals(1, tester.fatalCalled); err != nil {
+				t.Errorf("the suite should not run: %s", err)
+			}
+		})
+	}
+}
+
+func TestInvalidRegexStepDefinition(t *testing.T) {
+	tester := &mockTester{}
+	suite := NewSuite(tester)
+	suite.AddRegexStep(regexp.MustCompile(`I add (\d+) and (\d+)`), func(t StepTest, ctx Context, a int) {})
+	suite.Run()
+
+	if err := assert.Equals(1, tester.fatalCalled); err != nil {
+		t.Errorf("the suite should not run: %s", err)
+	}
+}
+
 func TestFailureOutput(t *testing.T) {
 	testCases := []struct {
 		name           string
@@ -216,6 +1477,13 @@ func TestFailureOutput(t *testing.T) {
 		{name: "passes", f: pass, expectedErrors: nil},
 		{name: "returns error", f: failure, expectedErrors: []string{"the step failed"}},
 		{name: "step panics", f: panics, expectedErrors: []string{"the step panicked"}},
+		{name: "returns an error", f: func(StepTest, Context) error { return errors.New("the step failed") },
+			expectedErrors: []string{"the step failed"}},
+		{name: "returns nil", f: func(StepTest, Context) error { return nil }, expectedErrors: nil},
+		{name: "returns ErrPending", f: func(StepTest, Context) error { return ErrPending }, expectedErrors: nil},
+		{name: "returns ErrSkip", f: func(StepTest, Context) error { return ErrSkip }, expectedErrors: nil},
+		{name: "returns wrapped ErrPending", f: func(StepTest, Context) error { return fmt.Errorf("later: %w", ErrPending) },
+			expectedErrors: nil},
 	}
 
 	for _, testCase := range testCases {
@@ -223,7 +1491,7 @@ func TestFailureOutput(t *testing.T) {
 			def := stepDef{f: testCase.f}
 
 			tester := &mockTester{}
-			def.run(NewContext(), tester, nil)
+			def.run(NewContext(), tester, nil, nil, "")
 			err := assert.Equals(testCase.expectedErrors, tester.errors)
 			if err != nil {
 				t.Fatal(err)
@@ -232,6 +1500,57 @@ func TestFailureOutput(t *testing.T) {
 	}
 }
 
+func TestLanguageHeader(t *testing.T) {
+	suite := NewSuite(t, WithFeaturesPath("features/i18n/de.feature"))
+	suite.AddStep(`ich addiere (\d+) und (\d+)`, add)
+	suite.AddStep(`ist das Ergebnis (\d+)`, check)
+
+	feature, err := suite.executeFeature("features/i18n/de.feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := assert.Equals("Funktionalität", feature.Keyword); err != nil {
+		t.Error(err)
+	}
+
+	if err := assert.Equals("Szenario", feature.Elements[0].Keyword); err != nil {
+		t.Error(err)
+	}
+
+	if err := assert.Equals("passed", feature.Elements[0].Steps[1].StepResult.RunStatus); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWithLanguage(t *testing.T) {
+	suite := NewSuite(t, WithFeaturesPath("features/i18n/pl.feature"), WithLanguage("pl"))
+	suite.AddStep(`dodam (\d+) i (\d+)`, add)
+	suite.AddStep(`wynik wynosi (\d+)`, check)
+
+	feature, err := suite.executeFeature("features/i18n/pl.feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := assert.Equals("Funkcja", feature.Keyword); err != nil {
+		t.Error(err)
+	}
+
+	if err := assert.Equals("Jeżeli ", feature.Elements[0].Steps[0].Keyword); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWithUnknownLanguage(t *testing.T) {
+	tester := &mockTester{}
+	NewSuite(tester, WithLanguage("xx"))
+
+	if err := assert.Equals(1, tester.fatalCalled); err != nil {
+		t.Error(err)
+	}
+}
+
 func addf(_ StepTest, ctx Context, var1, var2 float32) {
 	res := var1 + var2
 	ctx.Set("sumRes", res)
@@ -283,6 +1602,7 @@ func pass(_ StepTest, _ Context) {}
 
 type mockTester struct {
 	fatalCalled int
+	fatals      []string
 	errors      []string
 }
 
@@ -295,7 +1615,9 @@ func (m *mockTester) Fatal(...interface{}) {
 	m.fatalCalled++
 }
 
-func (m *mockTester) Fatalf(string, ...interface{}) {
+func (m *mockTester) Fatalf(format string, a ...interface{}) {
+	m.fatalCalled++
+	m.fatals = append(m.fatals, fmt.Sprintf(format, a...))
 }
 
 func (m *mockTester) Error(a ...interface{}) {
@@ -318,3 +1640,82 @@ func (m *mockTester) FailNow() {
 func (m *mockTester) Run(_ string, _ func(t *testing.T)) bool {
 	return true
 }
+
+// stepStatuses returns statuses of the steps of every scenario of the feature
+func stepStatuses(feature cucumber.Feature) [][]string {
+	var statuses [][]string
+
+	for _, scenario := range feature.Elements {
+		var scenarioStatuses []string
+		for _, step := range scenario.Steps {
+			scenarioStatuses = append(scenarioStatuses, step.StepResult.RunStatus)
+		}
+
+		statuses = append(statuses, scenarioStatuses)
+	}
+
+	return statuses
+}
+
+// isolatedTestEnv and isolatedResultEnv tell the test binary started by runIsolated
+// which test runs the isolated function and where the function's result should be written
+const (
+	isolatedTestEnv   = "GOBDD_ISOLATED_TEST"
+	isolatedResultEnv = "GOBDD_ISOLATED_RESULT"
+)
+
+// runIsolated runs the function in a new process of the test binary which runs only the current test,
+// so failures of the function don't fail the current test and don't show up in its output.
+// The function should store everything the test checks in result, which is passed back as JSON.
+// It returns false if the function failed.
+func runIsolated(t *testing.T, result interface{}, f func(t *testing.T)) bool {
+	if os.Getenv(isolatedTestEnv) == t.Name() {
+		defer func() {
+			b, err := json.Marshal(result)
+			if err == nil {
+				err = ioutil.WriteFile(os.Getenv(isolatedResultEnv), b, 0600)
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+		}()
+
+		f(t)
+
+		// the rest of the test runs in the parent process
+		t.SkipNow()
+	}
+
+	file, err := ioutil.TempFile("", "gobdd-isolated-*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file.Close()
+	defer os.Remove(file.Name())
+
+	var pattern []string
+	for _, name := range strings.Split(t.Name(), "/") {
+		pattern = append(pattern, "^"+regexp.QuoteMeta(name)+"$")
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run="+strings.Join(pattern, "/"))
+	cmd.Env = append(os.Environ(), isolatedTestEnv+"="+t.Name(), isolatedResultEnv+"="+file.Name())
+
+	output, runErr := cmd.CombinedOutput()
+	if _, failed := runErr.(*exec.ExitError); runErr != nil && !failed {
+		t.Fatal(runErr)
+	}
+
+	b, err := ioutil.ReadFile(file.Name())
+	if err != nil || len(b) == 0 {
+		t.Fatalf("the isolated test didn't write its result:\n%s", output)
+	}
+
+	if err := json.Unmarshal(b, result); err != nil {
+		t.Fatal(err)
+	}
+
+	return runErr == nil
+}
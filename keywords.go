@@ -0,0 +1,50 @@
+package gobdd
+
+import (
+	gherkin "github.com/cucumber/gherkin-go/v13"
+)
+
+// stepKeyword is the type of the step's keyword. Steps starting with And or But have the type of the previous step.
+type stepKeyword int
+
+const (
+	anyKeyword stepKeyword = iota
+	givenKeyword
+	whenKeyword
+	thenKeyword
+	conjunctionKeyword
+)
+
+func (k stepKeyword) String() string {
+	switch k {
+	case givenKeyword:
+		return "Given"
+	case whenKeyword:
+		return "When"
+	case thenKeyword:
+		return "Then"
+	default:
+		return "any keyword"
+	}
+}
+
+// keywordType returns the type of the step's keyword in the dialect.
+// The asterisk (*) is treated like And.
+func keywordType(dialect *gherkin.GherkinDialect, keyword string) stepKeyword {
+	if dialect == nil {
+		return anyKeyword
+	}
+
+	switch {
+	case contains(dialect.Keywords["and"], keyword), contains(dialect.Keywords["but"], keyword):
+		return conjunctionKeyword
+	case contains(dialect.Keywords["given"], keyword):
+		return givenKeyword
+	case contains(dialect.Keywords["when"], keyword):
+		return whenKeyword
+	case contains(dialect.Keywords["then"], keyword):
+		return thenKeyword
+	}
+
+	return anyKeyword
+}
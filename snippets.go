@@ -0,0 +1,72 @@
+package gobdd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	msgs "github.com/cucumber/messages-go/v12"
+)
+
+var snippetParamRegexp = regexp.MustCompile(`"[^"]*"|'[^']*'|-?\b\d+(?:\.\d+)?\b`)
+
+// stepSnippet returns the code of a step definition matching the step.
+// Quoted text and numbers in the step are replaced with {string}, {int} or {float} parameters.
+func stepSnippet(step *msgs.GherkinDocument_Feature_Step) string {
+	text := step.GetText()
+	params := []string{"t gobdd.StepTest", "ctx gobdd.Context"}
+
+	var expr strings.Builder
+
+	last := 0
+
+	for i, loc := range snippetParamRegexp.FindAllStringIndex(text, -1) {
+		expr.WriteString(escapeExpressionText(text[last:loc[0]]))
+		last = loc[1]
+
+		paramType, goType := "{int}", "int"
+
+		switch match := text[loc[0]:loc[1]]; {
+		case strings.HasPrefix(match, `"`) || strings.HasPrefix(match, `'`):
+			paramType, goType = "{string}", "string"
+		case strings.Contains(match, "."):
+			paramType, goType = "{float}", "float64"
+		}
+
+		expr.WriteString(paramType)
+		params = append(params, fmt.Sprintf("arg%d %s", i+1, goType))
+	}
+
+	expr.WriteString(escapeExpressionText(text[last:]))
+
+	if step.GetDataTable() != nil {
+		params = append(params, "table gobdd.DataTable")
+	}
+
+	if step.GetDocString() != nil {
+		params = append(params, "docString gobdd.DocString")
+	}
+
+	quoted := "`" + expr.String() + "`"
+	if strings.Contains(expr.String(), "`") {
+		quoted = strconv.Quote(expr.String())
+	}
+
+	return fmt.Sprintf("suite.AddStep(%s, func(%s) {\n\tt.Fatal(\"not implemented\")\n})", quoted, strings.Join(params, ", "))
+}
+
+// escapeExpressionText escapes characters which have special meaning in Cucumber expressions
+func escapeExpressionText(text string) string {
+	var b strings.Builder
+
+	for _, r := range text {
+		if strings.ContainsRune(`(){}/\`, r) {
+			b.WriteRune('\\')
+		}
+
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
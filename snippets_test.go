@@ -0,0 +1,67 @@
+package gobdd
+
+import (
+	"testing"
+
+	msgs "github.com/cucumber/messages-go/v12"
+	"github.com/go-bdd/assert"
+)
+
+func TestStepSnippet(t *testing.T) {
+	testCases := map[string]struct {
+		step     *msgs.GherkinDocument_Feature_Step
+		expected string
+	}{
+		"without parameters": {
+			step:     &msgs.GherkinDocument_Feature_Step{Text: "I eat a pizza"},
+			expected: "suite.AddStep(`I eat a pizza`, func(t gobdd.StepTest, ctx gobdd.Context) {\n\tt.Fatal(\"not implemented\")\n})",
+		},
+		"numbers and strings": {
+			step: &msgs.GherkinDocument_Feature_Step{Text: `I eat 2 pizzas "margherita" and 'capricciosa' for 12.50 and -3 cents`},
+			expected: "suite.AddStep(`I eat {int} pizzas {string} and {string} for {float} and {int} cents`, " +
+				"func(t gobdd.StepTest, ctx gobdd.Context, arg1 int, arg2 string, arg3 string, arg4 float64, arg5 int) {\n" +
+				"\tt.Fatal(\"not implemented\")\n})",
+		},
+		"numbers inside words": {
+			step:     &msgs.GherkinDocument_Feature_Step{Text: "I use ipv6 at step2"},
+			expected: "suite.AddStep(`I use ipv6 at step2`, func(t gobdd.StepTest, ctx gobdd.Context) {\n\tt.Fatal(\"not implemented\")\n})",
+		},
+		"special characters": {
+			step:     &msgs.GherkinDocument_Feature_Step{Text: "I eat (a lot of) {pizzas} a/b"},
+			expected: "suite.AddStep(`I eat \\(a lot of\\) \\{pizzas\\} a\\/b`, func(t gobdd.StepTest, ctx gobdd.Context) {\n\tt.Fatal(\"not implemented\")\n})",
+		},
+		"data table": {
+			step: &msgs.GherkinDocument_Feature_Step{Text: "the users:", Argument: &msgs.GherkinDocument_Feature_Step_DataTable_{
+				DataTable: &msgs.GherkinDocument_Feature_Step_DataTable{},
+			}},
+			expected: "suite.AddStep(`the users:`, func(t gobdd.StepTest, ctx gobdd.Context, table gobdd.DataTable) {\n\tt.Fatal(\"not implemented\")\n})",
+		},
+		"doc string": {
+			step: &msgs.GherkinDocument_Feature_Step{Text: "the text:", Argument: &msgs.GherkinDocument_Feature_Step_DocString_{
+				DocString: &msgs.GherkinDocument_Feature_Step_DocString{},
+			}},
+			expected: "suite.AddStep(`the text:`, func(t gobdd.StepTest, ctx gobdd.Context, docString gobdd.DocString) {\n\tt.Fatal(\"not implemented\")\n})",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if err := assert.Equals(testCase.expected, stepSnippet(testCase.step)); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestStepSnippetMatchesStep(t *testing.T) {
+	text := `I eat 2 pizzas "margherita" for 12.50 (with a/b) {tip}`
+	s := NewSuite(t)
+	expr, _, err := compileExpression(`I eat {int} pizzas {string} for {float} \(with a\/b\) \{tip\}`, s.parameterTypes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !expr.MatchString(text) {
+		t.Errorf("the expression %s should match %s", expr, text)
+	}
+}
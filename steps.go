@@ -1,32 +1,155 @@
 package gobdd
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"reflect"
+	"testing"
 )
 
+var (
+	// ErrPending can be returned by a step function to mark the step as pending (not implemented yet)
+	ErrPending = errors.New("the step is pending")
+	// ErrSkip can be returned by a step function to skip the step
+	ErrSkip = errors.New("the step is skipped")
+)
+
+var (
+	dataTableType    = reflect.TypeOf(DataTable{})
+	docStringType    = reflect.TypeOf(DocString{})
+	errorType        = reflect.TypeOf((*error)(nil)).Elem()
+	stepTestType     = reflect.TypeOf((*StepTest)(nil)).Elem()
+	testingTType     = reflect.TypeOf((*TestingT)(nil)).Elem()
+	testingPtrType   = reflect.TypeOf(&testing.T{})
+	contextType      = reflect.TypeOf(Context{})
+	stdContextType   = reflect.TypeOf((*context.Context)(nil)).Elem()
+	scenarioInfoType = reflect.TypeOf(ScenarioInfo{})
+)
+
+// validateStepFunc checks if the step function can be called. Parameters of injected types
+// (see isInjectedType) can be placed in any order, the step can have only one DataTable or DocString.
+// The function can return nothing, an error or a Context.
 func validateStepFunc(f interface{}) error {
 	value := reflect.ValueOf(f)
 	if value.Kind() != reflect.Func {
 		return errors.New("the parameter should be a function")
 	}
 
-	if value.Type().NumIn() < 2 {
-		return errors.New("the function should have StepTest and Context as the first argument")
+	arguments := 0
+
+	for i := 0; i < value.Type().NumIn(); i++ {
+		if isStepArgumentType(value.Type().In(i)) {
+			arguments++
+		}
 	}
 
-	val := value.Type().In(0)
+	if arguments > 1 {
+		return errors.New("the function can accept only one DataTable or DocString")
+	}
 
-	testingInterface := reflect.TypeOf((*StepTest)(nil)).Elem()
-	if !val.Implements(testingInterface) {
-		return errors.New("the function should have the StepTest as the first argument")
+	if n := value.Type().NumOut(); n > 1 {
+		return fmt.Errorf("the function can return only one value (error or Context) but it returns %d", n)
 	}
 
-	val = value.Type().In(1)
+	if n := value.Type().NumOut(); n == 1 && value.Type().Out(0) != errorType && value.Type().Out(0) != contextType {
+		return fmt.Errorf("the function can return only error or Context but it returns %s", value.Type().Out(0))
+	}
+
+	return nil
+}
+
+// validateStepParams checks if values captured by the step definition's expression can be passed
+// to the step function's parameters which aren't injected
+func validateStepParams(def stepDef) error {
+	fnType := reflect.TypeOf(def.f)
+
+	var captured []reflect.Type
+
+	for i := 0; i < fnType.NumIn(); i++ {
+		if !isInjectedType(fnType.In(i)) {
+			captured = append(captured, fnType.In(i))
+		}
+	}
+
+	groups := def.expr.NumSubexp()
+	if def.params != nil {
+		groups = len(def.params)
+	}
+
+	if groups != len(captured) {
+		return fmt.Errorf("the expression captures %d values but the function accepts %d", groups, len(captured))
+	}
+
+	for i, inType := range captured {
+		if i < len(def.params) && def.params[i].transformer.IsValid() {
+			if outType := def.params[i].transformer.Type().Out(0); !outType.AssignableTo(inType) {
+				return fmt.Errorf("the parameter {%s} is %s but the function expects %s as the argument %d",
+					def.params[i].name, outType, inType, i+1)
+			}
+
+			continue
+		}
+
+		if !isConvertible(inType) {
+			return fmt.Errorf("the argument %d has the unsupported type %s", i+1, inType)
+		}
+	}
+
+	return nil
+}
+
+// isStepArgumentType tells whether the type holds the argument (data table or doc string) placed below a step
+func isStepArgumentType(t reflect.Type) bool {
+	return t == dataTableType || t == docStringType
+}
+
+// isInjectedType tells whether the step function's parameter of the type is filled by GoBDD
+// instead of a value captured from the step's text
+func isInjectedType(t reflect.Type) bool {
+	switch t {
+	case stepTestType, testingTType, testingPtrType, contextType, stdContextType, scenarioInfoType:
+		return true
+	}
+
+	return isStepArgumentType(t)
+}
+
+// injectedValue returns the value of the step function's parameter of an injected type.
+// A *testing.T parameter is nil if the step isn't run by the testing package.
+func injectedValue(t reflect.Type, ctx Context, st TestingT, argument interface{}) reflect.Value {
+	switch t {
+	case stepTestType, testingTType:
+		return reflect.ValueOf(st)
+	case testingPtrType:
+		testingT, _ := st.(*testing.T)
+		return reflect.ValueOf(testingT)
+	case contextType:
+		return reflect.ValueOf(ctx)
+	case stdContextType:
+		stdCtx, _ := ctx.Get(stdContextKey{}, context.Background())
+		return reflect.ValueOf(stdCtx)
+	case scenarioInfoType:
+		info, _ := ctx.Get(ScenarioInfoKey{}, ScenarioInfo{})
+		return reflect.ValueOf(info)
+	}
+
+	return reflect.ValueOf(argument)
+}
+
+// validateTransformer checks if the parameter type's transformer is like func(string) (T, error)
+func validateTransformer(f interface{}) error {
+	value := reflect.ValueOf(f)
+	if value.Kind() != reflect.Func {
+		return errors.New("the transformer should be a function")
+	}
+
+	if value.Type().NumIn() != 1 || value.Type().In(0).Kind() != reflect.String {
+		return errors.New("the transformer should accept a single string")
+	}
 
-	n := val.ConvertibleTo(reflect.TypeOf((*Context)(nil)).Elem())
-	if !n {
-		return errors.New("the function should have Context as the second argument")
+	if value.Type().NumOut() != 2 || value.Type().Out(1) != errorType {
+		return errors.New("the transformer should return a value and an error")
 	}
 
 	return nil
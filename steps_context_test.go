@@ -9,7 +9,7 @@ import (
 
 func TestValidateStepFunc_Context(t *testing.T) {
 	testCases := map[string]interface{}{
-		"function with invalid first argument": func(int, context.Context) {},
+		"function with two DataTables": func(context.Context, gobdd.DataTable, gobdd.DataTable) {},
 	}
 
 	for name, testCase := range testCases {
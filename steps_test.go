@@ -1,14 +1,17 @@
 package gobdd
 
 import (
+	"context"
 	"testing"
 )
 
 func TestValidateStepFunc(t *testing.T) {
 	testCases := map[string]interface{}{
-		"function without arguments":           func() {},
-		"function with 1 argument":             func(StepTest) {},
-		"function with invalid first argument": func(int, Context) {},
+		"not a function":                        "step",
+		"function with two DataTables":          func(StepTest, Context, DataTable, DataTable) {},
+		"function with DataTable and DocString": func(DataTable, DocString) {},
+		"function returning int":                func(StepTest, Context) int { return 0 },
+		"function returning two values":         func(StepTest, Context) (Context, error) { return Context{}, nil },
 	}
 
 	for name, testCase := range testCases {
@@ -26,6 +29,44 @@ func TestValidateStepFunc_ValidFunction(t *testing.T) {
 	}
 }
 
+func TestValidateStepFunc_DataTable(t *testing.T) {
+	if err := validateStepFunc(func(StepTest, Context, int, DataTable) {}); err != nil {
+		t.Errorf("the test should NOT fail for the function: %s", err)
+	}
+}
+
+func TestValidateStepFunc_DocString(t *testing.T) {
+	if err := validateStepFunc(func(StepTest, Context, DocString) {}); err != nil {
+		t.Errorf("the test should NOT fail for the function: %s", err)
+	}
+}
+
+func TestValidateStepFunc_InjectedArguments(t *testing.T) {
+	testCases := map[string]interface{}{
+		"function without arguments":            func() {},
+		"function with only StepTest":           func(StepTest) {},
+		"function with only captured arguments": func(int, string) {},
+		"function with Context first":           func(Context, StepTest, int) {},
+		"function with DataTable in the middle": func(StepTest, DataTable, int) {},
+		"function with DocString first":         func(DocString, int) error { return nil },
+		"function with injected types":          func(*testing.T, context.Context, ScenarioInfo, TestingT) {},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if err := validateStepFunc(testCase); err != nil {
+				t.Errorf("the test should NOT fail for the function: %s", err)
+			}
+		})
+	}
+}
+
+func TestValidateStepFunc_ReturnError(t *testing.T) {
+	if err := validateStepFunc(func(StepTest, Context) error { return nil }); err != nil {
+		t.Errorf("step function returning an error should NOT fail validation: %s", err)
+	}
+}
+
 func TestValidateStepFunc_ReturnContext(t *testing.T) {
 	if err := validateStepFunc(func(StepTest, Context) Context { return Context{} }); err != nil {
 		t.Errorf("step function returning a context should NOT fail validation: %s", err)
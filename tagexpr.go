@@ -0,0 +1,225 @@
+package gobdd
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// tagExpression is a parsed Cucumber tag expression like `@api and not @slow`
+type tagExpression interface {
+	evaluate(tags []string) bool
+}
+
+type tagLiteral string
+
+func (e tagLiteral) evaluate(tags []string) bool {
+	return contains(tags, string(e))
+}
+
+type tagNot struct {
+	expr tagExpression
+}
+
+func (e tagNot) evaluate(tags []string) bool {
+	return !e.expr.evaluate(tags)
+}
+
+type tagAnd struct {
+	left, right tagExpression
+}
+
+func (e tagAnd) evaluate(tags []string) bool {
+	return e.left.evaluate(tags) && e.right.evaluate(tags)
+}
+
+type tagOr struct {
+	left, right tagExpression
+}
+
+func (e tagOr) evaluate(tags []string) bool {
+	return e.left.evaluate(tags) || e.right.evaluate(tags)
+}
+
+type tagTrue struct{}
+
+func (tagTrue) evaluate([]string) bool {
+	return true
+}
+
+// parseTagExpression parses expressions built of tags, `and`, `or`, `not` and parentheses.
+// The `not` operator binds tighter than `and`, which binds tighter than `or`.
+// Spaces and parentheses inside a tag have to be escaped with a backslash.
+// An empty expression matches every set of tags.
+func parseTagExpression(expr string) (tagExpression, error) {
+	tokens, err := tokenizeTagExpression(expr)
+	if err != nil {
+		return nil, fmt.Errorf("tag expression %q could not be parsed: %v", expr, err)
+	}
+
+	if len(tokens) == 0 {
+		return tagTrue{}, nil
+	}
+
+	p := &tagParser{tokens: tokens}
+
+	result, err := p.parseOr()
+	if err == nil && p.pos < len(p.tokens) {
+		if p.tokens[p.pos] == ")" {
+			err = fmt.Errorf("unmatched )")
+		} else {
+			err = fmt.Errorf("expected an operator before %s", p.tokens[p.pos])
+		}
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("tag expression %q could not be parsed: %v", expr, err)
+	}
+
+	return result, nil
+}
+
+func tokenizeTagExpression(expr string) ([]string, error) {
+	var tokens []string
+	var token strings.Builder
+
+	escaped := false
+	flush := func() {
+		if token.Len() > 0 {
+			tokens = append(tokens, token.String())
+			token.Reset()
+		}
+	}
+
+	for _, r := range expr {
+		switch {
+		case escaped:
+			if r != '(' && r != ')' && r != '\\' && !unicode.IsSpace(r) {
+				return nil, fmt.Errorf("illegal escape before %q", r)
+			}
+
+			token.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case unicode.IsSpace(r):
+			flush()
+		case r == '(' || r == ')':
+			flush()
+			tokens = append(tokens, string(r))
+		default:
+			token.WriteRune(r)
+		}
+	}
+
+	if escaped {
+		return nil, fmt.Errorf("the expression cannot end with an escape character")
+	}
+
+	flush()
+
+	return tokens, nil
+}
+
+type tagParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *tagParser) next() (string, bool) {
+	if p.pos >= len(p.tokens) {
+		return "", false
+	}
+
+	token := p.tokens[p.pos]
+	p.pos++
+
+	return token, true
+}
+
+func (p *tagParser) accept(token string) bool {
+	if p.pos < len(p.tokens) && p.tokens[p.pos] == token {
+		p.pos++
+		return true
+	}
+
+	return false
+}
+
+func (p *tagParser) parseOr() (tagExpression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.accept("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = tagOr{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *tagParser) parseAnd() (tagExpression, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.accept("and") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		left = tagAnd{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *tagParser) parseNot() (tagExpression, error) {
+	if p.accept("not") {
+		expr, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		return tagNot{expr: expr}, nil
+	}
+
+	return p.parseOperand()
+}
+
+func (p *tagParser) parseOperand() (tagExpression, error) {
+	token, ok := p.next()
+	if !ok {
+		return nil, fmt.Errorf("expected a tag at the end of the expression")
+	}
+
+	switch token {
+	case "(":
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if !p.accept(")") {
+			return nil, fmt.Errorf("unmatched (")
+		}
+
+		return expr, nil
+	case ")", "and", "or":
+		return nil, fmt.Errorf("expected a tag but got %s", token)
+	}
+
+	if !strings.HasPrefix(token, "@") {
+		return nil, fmt.Errorf("the tag %s has to start with @", token)
+	}
+
+	return tagLiteral(token), nil
+}
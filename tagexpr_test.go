@@ -0,0 +1,67 @@
+package gobdd
+
+import (
+	"testing"
+
+	"github.com/go-bdd/assert"
+)
+
+func TestParseTagExpression(t *testing.T) {
+	testCases := []struct {
+		expr     string
+		tags     []string
+		expected bool
+	}{
+		{expr: "", tags: nil, expected: true},
+		{expr: "@api", tags: []string{"@api"}, expected: true},
+		{expr: "@api", tags: []string{"@web"}, expected: false},
+		{expr: "not @wip", tags: []string{"@wip"}, expected: false},
+		{expr: "not not @wip", tags: []string{"@wip"}, expected: true},
+		{expr: "@api and not @slow", tags: []string{"@api"}, expected: true},
+		{expr: "@api and not @slow", tags: []string{"@api", "@slow"}, expected: false},
+		{expr: "@a or @b and @c", tags: []string{"@a"}, expected: true},
+		{expr: "(@a or @b) and @c", tags: []string{"@a"}, expected: false},
+		{expr: "(@smoke or @critical) and not @wip", tags: []string{"@critical"}, expected: true},
+		{expr: "(@smoke or @critical) and not @wip", tags: []string{"@smoke", "@wip"}, expected: false},
+		{expr: "((@a))", tags: []string{"@a"}, expected: true},
+		{expr: `@with\ space`, tags: []string{"@with space"}, expected: true},
+		{expr: `@x\(1\)`, tags: []string{"@x(1)"}, expected: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.expr, func(t *testing.T) {
+			expr, err := parseTagExpression(testCase.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := assert.Equals(testCase.expected, expr.evaluate(testCase.tags)); err != nil {
+				t.Errorf("%s for tags %v", err, testCase.tags)
+			}
+		})
+	}
+}
+
+func TestParseTagExpression_Errors(t *testing.T) {
+	testCases := []string{
+		"@a and",
+		"or @a",
+		"not",
+		"@a @b",
+		"(@a",
+		"@a)",
+		"()",
+		"@a adn @b",
+		"api",
+		`@a\b`,
+		`@a\`,
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			if _, err := parseTagExpression(testCase); err == nil {
+				t.Errorf("the expression %q should be invalid", testCase)
+			}
+		})
+	}
+}